@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/fasthttp/router"
 	"github.com/nspcc-dev/neo-go/cli/flags"
@@ -34,6 +35,10 @@ import (
 
 const (
 	defaultObjectSize = int64(1 << 21) // 2MB
+
+	// networkInfoUpdateInterval is the interval of network parameters (e.g.
+	// the current epoch) updates.
+	networkInfoUpdateInterval = time.Minute
 )
 
 type (
@@ -77,6 +82,7 @@ type (
 
 	GateMetricsProvider interface {
 		SetHealth(int32)
+		ArchiveCompleted(added, failed int, aborted bool)
 		Unregister()
 	}
 )
@@ -246,6 +252,17 @@ func (m *gateMetrics) SetHealth(status int32) {
 	m.provider.SetHealth(status)
 }
 
+func (m *gateMetrics) ArchiveCompleted(added, failed int, aborted bool) {
+	m.mu.RLock()
+	if !m.enabled {
+		m.mu.RUnlock()
+		return
+	}
+	m.mu.RUnlock()
+
+	m.provider.ArchiveCompleted(added, failed, aborted)
+}
+
 func (m *gateMetrics) Shutdown() {
 	m.mu.Lock()
 	if m.enabled {
@@ -330,7 +347,7 @@ func (a *app) setHealthStatus() {
 
 func (a *app) Serve(ctx context.Context) {
 	uploadRoutes := uploader.New(ctx, a.AppParams(), a.settings.Uploader, a.signer)
-	downloadRoutes := downloader.New(ctx, a.AppParams(), a.settings.Downloader, a.signer)
+	downloadRoutes := downloader.New(ctx, a.AppParams(), a.settings.Downloader, a.signer, a.metrics)
 
 	// Configure router.
 	a.configureRouter(uploadRoutes, downloadRoutes)
@@ -338,6 +355,8 @@ func (a *app) Serve(ctx context.Context) {
 	a.startServices()
 	a.initServers(ctx)
 
+	go a.updateNetworkSettingsPeriodically(ctx)
+
 	for i := range a.servers {
 		go func(i int) {
 			a.log.Info("starting server", zap.String("address", a.servers[i].Address()))
@@ -406,6 +425,42 @@ func (a *app) configReload(ctx context.Context) {
 func (a *app) updateSettings(ctx context.Context) {
 	a.settings.Uploader.SetDefaultTimestamp(a.cfg.GetBool(cfgUploaderHeaderEnableDefaultTimestamp))
 	a.settings.Downloader.SetZipCompression(a.cfg.GetBool(cfgZipCompression))
+	zipConcurrency := a.cfg.GetInt32(cfgZipConcurrency)
+	if zipConcurrency <= 0 {
+		a.log.Warn("invalid zip concurrency, using default",
+			zap.Int32("value", zipConcurrency), zap.Int("default", defaultZipConcurrency))
+		zipConcurrency = defaultZipConcurrency
+	}
+	a.settings.Downloader.SetZipConcurrency(zipConcurrency)
+	a.settings.Downloader.SetZipMemoryBudget(a.cfg.GetUint64(cfgZipMemoryBudget))
+	zipErrorMode, err := downloader.ParseArchiveErrorMode(a.cfg.GetString(cfgZipOnError))
+	if err != nil {
+		a.log.Warn("invalid zip error mode, using default", zap.Error(err))
+	}
+	a.settings.Downloader.SetZipErrorMode(zipErrorMode)
+	a.settings.Downloader.SetZipContentLength(a.cfg.GetBool(cfgZipContentLength))
+	if a.cfg.GetBool(cfgZipContentLength) && !a.cfg.GetBool(cfgZipCompression) && zipErrorMode != downloader.ArchiveErrorAbort {
+		a.log.Warn("zip.on_error is ignored for zip archives with Content-Length, failed objects abort the connection",
+			zap.String("on_error", a.cfg.GetString(cfgZipOnError)))
+	}
+	a.settings.Downloader.SetArchiveLimits(downloader.ArchiveLimits{
+		MaxObjects:  a.cfg.GetUint64(cfgZipMaxObjects),
+		MaxSize:     a.cfg.GetUint64(cfgZipMaxSize),
+		MaxDuration: a.cfg.GetDuration(cfgZipMaxDuration),
+		MaxStreams:  a.cfg.GetInt32(cfgZipMaxStreams),
+	})
+	a.settings.Downloader.SetCachePolicies(fetchCachePolicies(a.log, a.cfg))
+	a.settings.Downloader.SetCompression(fetchCompression(a.log, a.cfg))
+	a.settings.Downloader.SetAttributeHeaders(fetchAttributeHeaders(a.log, a.cfg))
+	a.settings.Downloader.SetWebsites(fetchWebsites(a.log, a.cfg))
+	a.settings.Downloader.SetVirtualHosts(fetchVirtualHosts(a.log, a.cfg))
+	a.settings.Downloader.SetDirectoryIndex(fetchDirectoryIndex(a.log, a.cfg))
+
+	a.updateNetworkSettings(ctx)
+}
+
+// updateNetworkSettings updates settings depending on network parameters.
+func (a *app) updateNetworkSettings(ctx context.Context) {
 	maxObjectSize := defaultObjectSize
 
 	ni, err := a.pool.NetworkInfo(ctx, client.PrmNetworkInfo{})
@@ -413,11 +468,33 @@ func (a *app) updateSettings(ctx context.Context) {
 		a.log.Error("get network info", zap.Error(err))
 	} else {
 		maxObjectSize = int64(ni.MaxObjectSize())
+
+		if durations, err := utils.NewEpochDurations(ni); err != nil {
+			a.log.Error("get epoch durations", zap.Error(err))
+		} else {
+			a.settings.Downloader.SetEpochDurations(*durations)
+		}
 	}
 
 	a.settings.Uploader.SetMaxObjectSize(maxObjectSize)
 }
 
+// updateNetworkSettingsPeriodically updates network parameters until the
+// context is done, so that the current epoch is known to handlers.
+func (a *app) updateNetworkSettingsPeriodically(ctx context.Context) {
+	ticker := time.NewTicker(networkInfoUpdateInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.updateNetworkSettings(ctx)
+		}
+	}
+}
+
 func (a *app) startServices() {
 	pprofConfig := metrics.Config{Enabled: a.cfg.GetBool(cfgPprofEnabled), Address: a.cfg.GetString(cfgPprofAddress)}
 	pprofService := metrics.NewPprofService(a.log, pprofConfig)
@@ -458,8 +535,31 @@ func (a *app) configureRouter(uploadRoutes *uploader.Uploader, downloadRoutes *d
 	a.log.Info("added path /get_by_attribute/{cid}/{attr_key}/{attr_val:*}")
 	r.GET("/zip/{cid}/{prefix:*}", a.logger(downloadRoutes.DownloadZipped))
 	a.log.Info("added path /zip/{cid}/{prefix}")
-
-	a.webServer.Handler = r.Handler
+	r.POST("/zip/{cid}", a.logger(downloadRoutes.DownloadZippedList))
+	a.log.Info("added path /zip/{cid}")
+	r.GET("/tar/{cid}/{prefix:*}", a.logger(downloadRoutes.DownloadTar))
+	a.log.Info("added path /tar/{cid}/{prefix}")
+	r.GET("/site/{cid}/{path:*}", a.logger(downloadRoutes.DownloadWebsite))
+	r.HEAD("/site/{cid}/{path:*}", a.logger(downloadRoutes.HeadWebsite))
+	a.log.Info("added path /site/{cid}/{path}")
+	r.GET("/search/{cid}", a.logger(downloadRoutes.SearchObjects))
+	a.log.Info("added path /search/{cid}")
+	r.GET("/list/{cid}", a.logger(downloadRoutes.ListObjects))
+	a.log.Info("added path /list/{cid}")
+	r.GET("/versions/{cid}/{path:*}", a.logger(downloadRoutes.ListVersions))
+	a.log.Info("added path /versions/{cid}/{path}")
+	r.GET("/{cid}/{path:*}", a.logger(downloadRoutes.DownloadByPath))
+	r.HEAD("/{cid}/{path:*}", a.logger(downloadRoutes.HeadByPath))
+	a.log.Info("added path /{cid}/{path}")
+
+	virtualHost := a.logger(downloadRoutes.DownloadVirtualHost)
+	a.webServer.Handler = func(c *fasthttp.RequestCtx) {
+		if downloadRoutes.IsVirtualHost(c) {
+			virtualHost(c)
+			return
+		}
+		r.Handler(c)
+	}
 }
 
 func (a *app) logger(h fasthttp.RequestHandler) fasthttp.RequestHandler {
@@ -0,0 +1,573 @@
+package downloader
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/tokens"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// archiveWriter writes objects as files of some archive format.
+type archiveWriter interface {
+	// createFile adds a file with the given name for the object and returns
+	// the writer for its payload.
+	createFile(obj *object.Object, name string) (io.Writer, error)
+	// Flush flushes buffered data to the underlying writer.
+	Flush() error
+	// Close finishes the archive, it doesn't close the underlying writer.
+	Close() error
+}
+
+// paddedArchiveWriter is implemented by archive writers declaring file sizes
+// before payloads, files with incomplete payloads must be padded to keep such
+// archives valid.
+type paddedArchiveWriter interface {
+	// padFile fills the rest of the current file with zeros.
+	padFile() error
+}
+
+// bufferedArchiveWriter is implemented by archive writers which can make use
+// of the whole file payload being in memory.
+type bufferedArchiveWriter interface {
+	// writeFile adds a file with the given name and payload for the object.
+	writeFile(obj *object.Object, name string, payload []byte) error
+}
+
+// archiveFormat describes archive type served by the gateway.
+type archiveFormat struct {
+	contentType string
+	extension   string
+	newWriter   func(w io.Writer) archiveWriter
+	// size calculates the exact archive size for the files, it's nil if the
+	// size can't be known before the archive is written.
+	size func([]archiveFile) (uint64, error)
+}
+
+// DownloadZipped handles zip by prefix requests.
+func (d *Downloader) DownloadZipped(c *fasthttp.RequestCtx) {
+	d.downloadArchive(c, d.zipFormat())
+}
+
+// zipFormat returns zip format according to the settings.
+func (d *Downloader) zipFormat() archiveFormat {
+	if d.settings.ZipCompression() {
+		return zipFormat(true)
+	}
+	if d.settings.ZipContentLength() {
+		return storedZipFormat()
+	}
+	return zipFormat(false)
+}
+
+// DownloadTar handles tar (optionally gzipped) by prefix requests.
+func (d *Downloader) DownloadTar(c *fasthttp.RequestCtx) {
+	format, err := tarFormat(string(c.QueryArgs().Peek("format")))
+	if err != nil {
+		d.log.Error("wrong archive format", zap.Error(err))
+		response.Error(c, err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	d.downloadArchive(c, format)
+}
+
+// downloadArchive streams objects with FilePath attribute matching the
+// requested prefix as the archive of the given format.
+func (d *Downloader) downloadArchive(c *fasthttp.RequestCtx, format archiveFormat) {
+	scid, _ := c.UserValue("cid").(string)
+	prefix, _ := url.QueryUnescape(c.UserValue("prefix").(string))
+	log := d.log.With(zap.String("cid", scid), zap.String("prefix", prefix))
+
+	extraFilters, err := searchFiltersFromQuery(c.QueryArgs())
+	if err != nil {
+		log.Error("invalid search filter", zap.Error(err))
+		response.Error(c, "invalid search filter: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	containerID, ok := d.archiveContainer(c, log, scid)
+	if !ok {
+		return
+	}
+
+	slot, ok := d.takeArchiveSlot(c, log)
+	if !ok {
+		return
+	}
+	defer slot.done()
+
+	naming := archiveNaming{
+		fallback: c.QueryArgs().GetBool("fallback"),
+	}
+	if c.QueryArgs().GetBool("strip_prefix") {
+		naming.stripDir = prefix[:strings.LastIndexByte(prefix, '/')+1]
+	}
+
+	filters := append(object.SearchFilters(nil), extraFilters...)
+	filters.AddFilter(object.AttributeFilePath, prefix, object.MatchCommonPrefix)
+
+	resSearch, err := d.searchObjects(c, containerID, filters)
+	if err != nil {
+		log.Error("could not search for objects", zap.Error(err))
+		response.Error(c, "could not search for objects: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+	results := []*client.ObjectListReader{resSearch}
+
+	if naming.fallback {
+		// objects without FilePath are matched by FileName, all of them are
+		// taken for the empty prefix to be named by FileName or ID
+		filters := append(object.SearchFilters(nil), extraFilters...)
+		filters.AddFilter(object.AttributeFilePath, "", object.MatchNotPresent)
+		if prefix != "" {
+			filters.AddFilter(object.AttributeFileName, prefix, object.MatchCommonPrefix)
+		}
+
+		resFallback, err := d.searchObjects(c, containerID, filters)
+		if err != nil {
+			resSearch.Close()
+			log.Error("could not search for objects without FilePath", zap.Error(err))
+			response.Error(c, "could not search for objects: "+err.Error(), fasthttp.StatusBadRequest)
+			return
+		}
+		results = append(results, resFallback)
+	}
+
+	d.streamArchive(c, archiveStream{
+		log:      log,
+		format:   format,
+		cnrID:    *containerID,
+		fileName: archiveFileName(prefix, format.extension),
+		iterate:  iterateSearchResults(results),
+		name: func(_ int, obj *object.Object) string {
+			return naming.name(obj)
+		},
+		unique: c.QueryArgs().GetBool("unique"),
+		slot:   slot,
+	})
+}
+
+// iterateSearchResults returns the function passing IDs from all the search
+// results one by one, the results are closed after iteration.
+func iterateSearchResults(results []*client.ObjectListReader) func(func(oid.ID) bool) error {
+	return func(f func(oid.ID) bool) error {
+		defer func() {
+			for _, res := range results {
+				res.Close()
+			}
+		}()
+
+		var stop bool
+		for _, res := range results {
+			err := res.Iterate(func(id oid.ID) bool {
+				stop = f(id)
+				return stop
+			})
+			if err != nil || stop {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// archiveContainer resolves the container of archive request and checks that
+// it exists, it also stores the bearer token of the request. It returns false
+// if the request has been already answered with an error.
+func (d *Downloader) archiveContainer(c *fasthttp.RequestCtx, log *zap.Logger, scid string) (*cid.ID, bool) {
+	containerID, err := utils.GetContainerID(d.appCtx, scid, d.containerResolver)
+	if err != nil {
+		log.Error("wrong container id", zap.Error(err))
+		response.Error(c, "wrong container id", fasthttp.StatusBadRequest)
+		return nil, false
+	}
+
+	if err = tokens.StoreBearerToken(c); err != nil {
+		log.Error("could not fetch and store bearer token", zap.Error(err))
+		response.Error(c, "could not fetch and store bearer token: "+err.Error(), fasthttp.StatusBadRequest)
+		return nil, false
+	}
+
+	// check if container exists here to be able to return 404 error,
+	// otherwise we get this error only in object iteration step
+	// and client get 200 OK.
+	if _, err = d.getContainer(*containerID); err != nil {
+		log.Error("could not check container existence", zap.Error(err))
+		if errors.Is(err, apistatus.ErrContainerNotFound) {
+			response.Error(c, "Not Found", fasthttp.StatusNotFound)
+			return nil, false
+		}
+		response.Error(c, "could not check container existence: "+err.Error(), fasthttp.StatusBadRequest)
+		return nil, false
+	}
+
+	return containerID, true
+}
+
+// archiveStream describes archive to be streamed.
+type archiveStream struct {
+	log    *zap.Logger
+	format archiveFormat
+	cnrID  cid.ID
+	btoken *bearer.Token
+	// fileName is the name of archive in Content-Disposition header, it's
+	// archive.<ext> if not set.
+	fileName string
+	// iterate passes IDs of objects to be archived.
+	iterate func(func(oid.ID) bool) error
+	// name returns archive file name by the object index and header.
+	name      func(int, *object.Object) string
+	errorMode ArchiveErrorMode
+	// unique makes archive file names unique adding a number to duplicates.
+	unique bool
+	limits ArchiveLimits
+	// slot is released when the stream is finished.
+	slot *archiveSlot
+	// ra limits the body to the range of archive (if set).
+	ra *httpRange
+}
+
+// streamArchive sets the reply body to the archive of objects described by
+// the stream, bearer token and error mode are set by the request and settings.
+func (d *Downloader) streamArchive(c *fasthttp.RequestCtx, stream archiveStream) {
+	stream.btoken = bearerToken(c)
+	stream.errorMode = d.settings.ZipErrorMode()
+	stream.limits = d.settings.ArchiveLimits()
+
+	if stream.format.size != nil {
+		d.streamSizedArchive(c, stream)
+		return
+	}
+
+	c.Response.Header.Set(fasthttp.HeaderContentType, stream.format.contentType)
+	c.Response.Header.Set(fasthttp.HeaderContentDisposition, stream.contentDisposition(c))
+	c.Response.SetStatusCode(http.StatusOK)
+	c.Response.SetBodyStream(d.archiveBody(stream), -1)
+}
+
+// contentDisposition returns Content-Disposition header value of the archive.
+func (s archiveStream) contentDisposition(c *fasthttp.RequestCtx) string {
+	fileName := s.fileName
+	if fileName == "" {
+		fileName = defaultArchiveBaseName + s.format.extension
+	}
+	return contentDisposition("attachment", responseFileName(c, fileName))
+}
+
+// archiveBody returns the reader of archive which is written in background.
+func (d *Downloader) archiveBody(s archiveStream) io.Reader {
+	log := s.log
+
+	if s.slot != nil {
+		s.slot.streamed = true
+	}
+
+	pr, pw := io.Pipe()
+	go func() {
+		var (
+			ctx    context.Context
+			cancel context.CancelFunc
+		)
+		if s.limits.MaxDuration > 0 {
+			ctx, cancel = context.WithTimeout(d.appCtx, s.limits.MaxDuration)
+		} else {
+			ctx, cancel = context.WithCancel(d.appCtx)
+		}
+
+		prefetcher := d.newObjectPrefetcher(s.cnrID, s.btoken)
+		prefetcher.start(ctx, s.iterate)
+		defer func() {
+			cancel()
+			prefetcher.drain()
+		}()
+
+		var out io.Writer = pw
+		if s.ra != nil {
+			out = &rangeWriter{w: pw, skip: s.ra.start, left: s.ra.length}
+		}
+
+		// connection errors are tracked to stop streaming if the client is gone
+		conn := &errorTrackingWriter{w: out}
+		bufWriter := bufio.NewWriter(conn)
+		archWriter := s.format.newWriter(bufWriter)
+
+		var (
+			bufZip   []byte
+			err      error
+			added    int
+			size     uint64
+			failures []archiveFailure
+			names    = make(uniqueNames)
+			limitErr error
+		)
+
+		durationExceeded := func() bool {
+			return errors.Is(ctx.Err(), context.DeadlineExceeded)
+		}
+
+		// finish closes the reply body, an error breaks the connection.
+		finish := func(err error) {
+			if errors.Is(err, errRangeWritten) {
+				err = nil
+			}
+			d.archiveCompleted(log, added, len(failures), err != nil)
+			_ = pw.CloseWithError(err)
+			if s.slot != nil {
+				s.slot.release()
+			}
+		}
+
+		for ch := range prefetcher.queue {
+			obj := <-ch
+
+			if durationExceeded() {
+				limitErr = fmt.Errorf("%w: %s", errArchiveDurationLimit, s.limits.MaxDuration)
+			} else if obj.err == nil {
+				limitErr = s.limits.check(uint64(added+len(failures)+1), size+obj.hdr.PayloadSize())
+			}
+			if limitErr != nil {
+				prefetcher.done(obj)
+				break
+			}
+
+			var fileName string
+			if obj.err == nil {
+				if bufZip == nil && obj.reserved == 0 {
+					bufZip = make([]byte, 3<<20) // the same as for upload
+				}
+				fileName = s.name(added+len(failures), &obj.hdr)
+				if s.unique {
+					fileName = names.unique(fileName)
+				}
+				obj.err = d.archiveObject(archWriter, &obj.hdr, fileName, obj.payload, bufZip)
+				if err = obj.payload.Close(); err != nil && obj.err == nil {
+					obj.err = fmt.Errorf("object body close error: %w", err)
+				}
+				obj.payload = nil
+			}
+
+			prefetcher.done(obj)
+
+			if conn.err != nil {
+				if !errors.Is(conn.err, errRangeWritten) {
+					log.Error("could not write archive", zap.Error(conn.err))
+				}
+				finish(conn.err)
+				return
+			}
+			if obj.err == nil {
+				added++
+				size += obj.hdr.PayloadSize()
+				continue
+			}
+
+			log.Error("failed to add object to archive", zap.String("oid", obj.id.EncodeToString()), zap.Error(obj.err))
+			id := obj.id
+			failures = append(failures, archiveFailure{id: &id, name: fileName, err: obj.err})
+			if s.errorMode == ArchiveErrorAbort {
+				finish(obj.err)
+				return
+			}
+		}
+		if limitErr == nil && durationExceeded() {
+			limitErr = fmt.Errorf("%w: %s", errArchiveDurationLimit, s.limits.MaxDuration)
+		}
+		if limitErr != nil {
+			log.Error("archive is truncated", zap.Error(limitErr))
+			failures = append(failures, archiveFailure{err: limitErr})
+			if s.errorMode == ArchiveErrorAbort {
+				finish(limitErr)
+				return
+			}
+		} else if prefetcher.iterErr != nil {
+			log.Error("iterating over selected objects failed", zap.Error(prefetcher.iterErr))
+			failures = append(failures, archiveFailure{err: fmt.Errorf("iterating over selected objects failed: %w", prefetcher.iterErr)})
+			if s.errorMode == ArchiveErrorAbort {
+				finish(prefetcher.iterErr)
+				return
+			}
+		} else if added+len(failures) == 0 {
+			log.Error("objects not found")
+		}
+
+		var reportErr error
+		switch {
+		case s.errorMode == ArchiveErrorReport && len(failures) != 0:
+			reportErr = writeArchiveReport(archWriter, failures)
+		case s.errorMode == ArchiveErrorSkip && limitErr != nil:
+			// skipped objects are only logged, but the archive truncated by
+			// the limit must not look complete
+			reportErr = writeArchiveReport(archWriter, []archiveFailure{{err: limitErr}})
+		}
+		if reportErr != nil {
+			log.Error("could not write archive error report", zap.Error(reportErr))
+		}
+
+		if err = archWriter.Close(); err == nil {
+			err = bufWriter.Flush()
+		}
+		if err != nil && !errors.Is(err, errRangeWritten) {
+			log.Error("close archive writer", zap.Error(err))
+		}
+
+		finish(err)
+	}()
+
+	return pr
+}
+
+// archiveCompleted logs the summary of streamed archive and updates metrics.
+func (d *Downloader) archiveCompleted(log *zap.Logger, added, failed int, aborted bool) {
+	fields := []zap.Field{zap.Int("added", added), zap.Int("failed", failed), zap.Bool("aborted", aborted)}
+	if failed != 0 || aborted {
+		log.Warn("archive streamed with errors", fields...)
+	} else {
+		log.Info("archive streamed", fields...)
+	}
+
+	d.metrics.ArchiveCompleted(added, failed, aborted)
+}
+
+func (d *Downloader) archiveObject(archWriter archiveWriter, obj *object.Object, name string, payload io.Reader, buf []byte) error {
+	if !isValidArchiveName(name) {
+		return fmt.Errorf("invalid file name '%s'", name)
+	}
+
+	err := writeArchiveFile(archWriter, obj, name, payload, buf)
+	if err != nil {
+		return err
+	}
+
+	if err = archWriter.Flush(); err != nil {
+		return fmt.Errorf("flush archive writer: %v", err)
+	}
+
+	return nil
+}
+
+// writeArchiveFile writes the object payload as the archive file, payloads
+// read to memory are passed as a whole if the writer supports it.
+func writeArchiveFile(archWriter archiveWriter, obj *object.Object, name string, payload io.Reader, buf []byte) error {
+	if buffered, ok := payload.(bufferedPayload); ok {
+		if bw, ok := archWriter.(bufferedArchiveWriter); ok {
+			if err := bw.writeFile(obj, name, buffered.data); err != nil {
+				return fmt.Errorf("write archive file: %v", err)
+			}
+			return nil
+		}
+	}
+
+	objWriter, err := archWriter.createFile(obj, name)
+	if err != nil {
+		return fmt.Errorf("create archive file: %v", err)
+	}
+
+	n, err := io.CopyBuffer(objWriter, payload, buf)
+	if err != nil {
+		err = fmt.Errorf("copy object payload to archive file: %v", err)
+	} else if uint64(n) != obj.PayloadSize() {
+		err = fmt.Errorf("payload size mismatch: header %d, received %d", obj.PayloadSize(), n)
+	}
+	if err != nil {
+		if pw, ok := archWriter.(paddedArchiveWriter); ok {
+			if padErr := pw.padFile(); padErr != nil {
+				return fmt.Errorf("%w, pad archive file: %v", err, padErr)
+			}
+		}
+		return err
+	}
+
+	return nil
+}
+
+// isValidArchiveName checks that the name can be used for a file in archive.
+func isValidArchiveName(name string) bool {
+	return len(name) != 0 && name[len(name)-1] != '/'
+}
+
+// archiveModTime returns modification time of archive file for the object,
+// it's taken from Timestamp attribute (current time is used if it's missing).
+func archiveModTime(obj *object.Object) time.Time {
+	if modTime, ok := objectTimestamp(obj); ok {
+		return modTime
+	}
+	return time.Now()
+}
+
+// archiveNaming defines how archive file names are derived from object
+// attributes.
+type archiveNaming struct {
+	// stripDir is removed from the beginning of names.
+	stripDir string
+	// fallback allows naming objects without valid FilePath by FileName or ID.
+	fallback bool
+}
+
+func (n archiveNaming) name(obj *object.Object) string {
+	name := getFilePath(obj)
+	if n.fallback && !isValidArchiveName(name) {
+		id, _ := obj.ID()
+		name = defaultArchiveName(obj, id)
+	}
+
+	if stripped := strings.TrimPrefix(name, n.stripDir); isValidArchiveName(stripped) {
+		name = stripped
+	}
+	return name
+}
+
+// uniqueNames tracks archive file names to avoid duplicates.
+type uniqueNames map[string]struct{}
+
+// unique returns the name if it's not used yet, otherwise a number is added
+// before its extension: "dir/file (1).txt".
+func (u uniqueNames) unique(name string) string {
+	res := name
+	if _, ok := u[res]; ok {
+		ext := path.Ext(name)
+		if len(ext) == len(path.Base(name)) {
+			// hidden file without extension
+			ext = ""
+		}
+		base := strings.TrimSuffix(name, ext)
+		for i := 1; ; i++ {
+			res = base + " (" + strconv.Itoa(i) + ")" + ext
+			if _, ok = u[res]; !ok {
+				break
+			}
+		}
+	}
+
+	u[res] = struct{}{}
+	return res
+}
+
+func getFilePath(obj *object.Object) string {
+	for _, attr := range obj.Attributes() {
+		if attr.Key() == object.AttributeFilePath {
+			return attr.Value()
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,82 @@
+package downloader
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// archiveRetryAfter is the value of Retry-After header sent when the limit of
+// concurrent archive streams is reached.
+const archiveRetryAfter = 10 * time.Second
+
+var (
+	errArchiveObjectsLimit  = errors.New("archive objects limit exceeded")
+	errArchiveSizeLimit     = errors.New("archive size limit exceeded")
+	errArchiveDurationLimit = errors.New("archive duration limit exceeded")
+)
+
+// ArchiveLimits restricts archive requests, zero values mean no limit.
+type ArchiveLimits struct {
+	// MaxObjects is the maximum number of objects in archive.
+	MaxObjects uint64
+	// MaxSize is the maximum total payload size of archive objects.
+	MaxSize uint64
+	// MaxDuration is the maximum duration of archive streaming.
+	MaxDuration time.Duration
+	// MaxStreams is the maximum number of archive requests served
+	// concurrently by the gateway.
+	MaxStreams int32
+}
+
+// check returns an error if the archive with the given number of objects and
+// total payload size exceeds the limits.
+func (l ArchiveLimits) check(objects, size uint64) error {
+	if l.MaxObjects != 0 && objects > l.MaxObjects {
+		return fmt.Errorf("%w: %d", errArchiveObjectsLimit, l.MaxObjects)
+	}
+	if l.MaxSize != 0 && size > l.MaxSize {
+		return fmt.Errorf("%w: %d bytes", errArchiveSizeLimit, l.MaxSize)
+	}
+	return nil
+}
+
+// archiveSlot is a slot of concurrent archive streams limit taken by the
+// request. It's released by the request handler if the archive hasn't been
+// started to stream, otherwise the stream releases it when finished.
+type archiveSlot struct {
+	streams  *atomic.Int32
+	streamed bool
+}
+
+// done releases the slot if the archive hasn't been started to stream.
+func (s *archiveSlot) done() {
+	if !s.streamed {
+		s.release()
+	}
+}
+
+func (s *archiveSlot) release() {
+	s.streams.Add(-1)
+}
+
+// takeArchiveSlot checks the limit of concurrent archive streams, it answers
+// with 503 status code and returns false if the limit is reached.
+func (d *Downloader) takeArchiveSlot(c *fasthttp.RequestCtx, log *zap.Logger) (*archiveSlot, bool) {
+	limit := d.settings.ArchiveLimits().MaxStreams
+	if streams := d.archiveStreams.Add(1); limit > 0 && streams > limit {
+		d.archiveStreams.Add(-1)
+		log.Warn("too many concurrent archive streams", zap.Int32("limit", limit))
+		response.Error(c, "too many concurrent archive downloads, try again later", fasthttp.StatusServiceUnavailable)
+		c.Response.Header.Set(fasthttp.HeaderRetryAfter, strconv.Itoa(int(archiveRetryAfter/time.Second)))
+		return nil, false
+	}
+
+	return &archiveSlot{streams: &d.archiveStreams}, true
+}
@@ -0,0 +1,79 @@
+package downloader
+
+import (
+	"testing"
+	"time"
+
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func TestArchiveLimitsCheck(t *testing.T) {
+	require.NoError(t, ArchiveLimits{}.check(1<<20, 1<<40))
+
+	limits := ArchiveLimits{MaxObjects: 10, MaxSize: 100}
+	require.NoError(t, limits.check(10, 100))
+	require.ErrorIs(t, limits.check(11, 100), errArchiveObjectsLimit)
+	require.ErrorIs(t, limits.check(10, 101), errArchiveSizeLimit)
+}
+
+func TestTakeArchiveSlot(t *testing.T) {
+	d := &Downloader{settings: new(Settings)}
+	d.settings.SetArchiveLimits(ArchiveLimits{MaxStreams: 1})
+
+	var c fasthttp.RequestCtx
+	slot, ok := d.takeArchiveSlot(&c, zap.NewNop())
+	require.True(t, ok)
+
+	var rejected fasthttp.RequestCtx
+	_, ok = d.takeArchiveSlot(&rejected, zap.NewNop())
+	require.False(t, ok)
+	require.Equal(t, fasthttp.StatusServiceUnavailable, rejected.Response.StatusCode())
+	require.Equal(t, "10", string(rejected.Response.Header.Peek(fasthttp.HeaderRetryAfter)))
+
+	// the slot of the streamed archive is released by the stream only
+	slot.streamed = true
+	slot.done()
+	_, ok = d.takeArchiveSlot(&rejected, zap.NewNop())
+	require.False(t, ok)
+
+	slot.release()
+	slot, ok = d.takeArchiveSlot(&c, zap.NewNop())
+	require.True(t, ok)
+	slot.done()
+	require.Zero(t, d.archiveStreams.Load())
+
+	// the limit is reloadable
+	d.settings.SetArchiveLimits(ArchiveLimits{MaxDuration: time.Minute})
+	for i := 0; i < 3; i++ {
+		_, ok = d.takeArchiveSlot(&c, zap.NewNop())
+		require.True(t, ok)
+	}
+}
+
+func TestHeadArchiveObjectsLimit(t *testing.T) {
+	d := &Downloader{settings: new(Settings)}
+
+	var iterated int
+	s := archiveStream{
+		log:    zap.NewNop(),
+		limits: ArchiveLimits{MaxObjects: 2},
+		iterate: func(f func(oid.ID) bool) error {
+			for i := 0; i < 10; i++ {
+				iterated++
+				if f(oidtest.ID()) {
+					break
+				}
+			}
+			return nil
+		},
+	}
+
+	// objects are not headed if there are too many of them
+	_, err := d.headArchiveObjects(s)
+	require.ErrorIs(t, err, errArchiveObjectsLimit)
+	require.Equal(t, 3, iterated)
+}
@@ -0,0 +1,192 @@
+package downloader
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// archiveEntry is an element of the object list of archive request, the object
+// is specified either by ID or by FilePath attribute. Name overrides the name
+// of the archive file.
+type archiveEntry struct {
+	ObjectID string `json:"objectId,omitempty"`
+	FilePath string `json:"filePath,omitempty"`
+	Name     string `json:"name,omitempty"`
+}
+
+// archiveItem is the resolved archive entry.
+type archiveItem struct {
+	id   oid.ID
+	name string
+}
+
+// DownloadZippedList handles requests for zip archive of the objects listed in
+// the request body.
+func (d *Downloader) DownloadZippedList(c *fasthttp.RequestCtx) {
+	scid, _ := c.UserValue("cid").(string)
+	log := d.log.With(zap.String("cid", scid))
+
+	body := c.RequestBodyStream()
+	if body == nil {
+		body = bytes.NewReader(c.PostBody())
+	}
+
+	entries, err := parseArchiveEntries(body)
+	if err != nil {
+		log.Error("invalid object list", zap.Error(err))
+		response.Error(c, "invalid object list: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	if err = d.settings.ArchiveLimits().check(uint64(len(entries)), 0); err != nil {
+		log.Error("invalid object list", zap.Error(err))
+		response.Error(c, "invalid object list: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	containerID, ok := d.archiveContainer(c, log, scid)
+	if !ok {
+		return
+	}
+
+	slot, ok := d.takeArchiveSlot(c, log)
+	if !ok {
+		return
+	}
+	defer slot.done()
+
+	items := make([]archiveItem, len(entries))
+	for i := range entries {
+		if items[i], err = d.resolveArchiveEntry(c, *containerID, entries[i]); err != nil {
+			log.Error("could not resolve archive entry", zap.Int("entry", i), zap.Error(err))
+			msg := fmt.Sprintf("entry %d: %v", i, err)
+			if errors.Is(err, errObjectNotFound) ||
+				errors.Is(err, apistatus.ErrObjectNotFound) ||
+				errors.Is(err, apistatus.ErrObjectAlreadyRemoved) {
+				response.Error(c, msg, fasthttp.StatusNotFound)
+				return
+			}
+			response.Error(c, msg, fasthttp.StatusBadRequest)
+			return
+		}
+	}
+
+	iterate := func(f func(oid.ID) bool) error {
+		for i := range items {
+			if f(items[i].id) {
+				break
+			}
+		}
+		return nil
+	}
+
+	d.streamArchive(c, archiveStream{
+		log:     log,
+		format:  d.zipFormat(),
+		cnrID:   *containerID,
+		iterate: iterate,
+		name: func(i int, _ *object.Object) string {
+			return items[i].name
+		},
+		slot: slot,
+	})
+}
+
+// parseArchiveEntries decodes and validates the JSON list of archive entries.
+func parseArchiveEntries(r io.Reader) ([]archiveEntry, error) {
+	var entries []archiveEntry
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("decode json: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("empty list")
+	}
+
+	for i, e := range entries {
+		if (e.ObjectID == "") == (e.FilePath == "") {
+			return nil, fmt.Errorf("entry %d: exactly one of objectId and filePath must be set", i)
+		}
+		if e.ObjectID != "" {
+			var id oid.ID
+			if err := id.DecodeString(e.ObjectID); err != nil {
+				return nil, fmt.Errorf("entry %d: wrong object id: %w", i, err)
+			}
+		}
+		if e.Name != "" && !isValidArchiveName(e.Name) {
+			return nil, fmt.Errorf("entry %d: invalid file name '%s'", i, e.Name)
+		}
+	}
+
+	return entries, nil
+}
+
+// resolveArchiveEntry finds the object of the archive entry and its archive
+// file name.
+func (d *Downloader) resolveArchiveEntry(c *fasthttp.RequestCtx, cnrID cid.ID, e archiveEntry) (archiveItem, error) {
+	var item archiveItem
+
+	if e.FilePath != "" {
+		id, err := d.findObject(c, &cnrID, object.AttributeFilePath, e.FilePath)
+		if err != nil {
+			return item, err
+		}
+
+		item.id = id
+		item.name = e.FilePath
+	} else {
+		_ = item.id.DecodeString(e.ObjectID) // checked by parseArchiveEntries
+
+		var prm client.PrmObjectHead
+		if btoken := bearerToken(c); btoken != nil {
+			prm.WithBearerToken(*btoken)
+		}
+
+		obj, err := d.pool.ObjectHead(d.appCtx, cnrID, item.id, d.signer, prm)
+		if err != nil {
+			return item, fmt.Errorf("could not head object: %w", err)
+		}
+		item.name = defaultArchiveName(obj, item.id)
+	}
+
+	if e.Name != "" {
+		item.name = e.Name
+	}
+	if !isValidArchiveName(item.name) {
+		return item, fmt.Errorf("invalid file name '%s'", item.name)
+	}
+
+	return item, nil
+}
+
+// defaultArchiveName returns archive file name for the object requested by ID:
+// its FilePath, FileName or the ID itself.
+func defaultArchiveName(obj *object.Object, id oid.ID) string {
+	var fileName string
+	for _, attr := range obj.Attributes() {
+		switch attr.Key() {
+		case object.AttributeFilePath:
+			if isValidArchiveName(attr.Value()) {
+				return attr.Value()
+			}
+		case object.AttributeFileName:
+			fileName = attr.Value()
+		}
+	}
+
+	if isValidArchiveName(fileName) {
+		return fileName
+	}
+	return id.EncodeToString()
+}
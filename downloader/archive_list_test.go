@@ -0,0 +1,60 @@
+package downloader
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseArchiveEntries(t *testing.T) {
+	id := oidtest.ID()
+
+	entries, err := parseArchiveEntries(strings.NewReader(`[
+		{"objectId": "` + id.EncodeToString() + `"},
+		{"filePath": "dir/file.txt", "name": "renamed.txt"}
+	]`))
+	require.NoError(t, err)
+	require.Equal(t, []archiveEntry{
+		{ObjectID: id.EncodeToString()},
+		{FilePath: "dir/file.txt", Name: "renamed.txt"},
+	}, entries)
+
+	for _, body := range []string{
+		``,
+		`{}`,
+		`[]`,
+		`[{}]`,
+		`[{"objectId": "` + id.EncodeToString() + `", "filePath": "file.txt"}]`,
+		`[{"objectId": "invalid"}]`,
+		`[{"filePath": "file.txt", "name": "dir/"}]`,
+	} {
+		_, err = parseArchiveEntries(strings.NewReader(body))
+		require.Error(t, err, body)
+	}
+}
+
+func TestDefaultArchiveName(t *testing.T) {
+	id := oidtest.ID()
+
+	attribute := func(key, val string) object.Attribute {
+		attr := object.NewAttribute()
+		attr.SetKey(key)
+		attr.SetValue(val)
+		return *attr
+	}
+
+	obj := object.New()
+	require.Equal(t, id.EncodeToString(), defaultArchiveName(obj, id))
+
+	obj.SetAttributes(attribute(object.AttributeFileName, "name.txt"))
+	require.Equal(t, "name.txt", defaultArchiveName(obj, id))
+
+	obj.SetAttributes(attribute(object.AttributeFileName, "name.txt"), attribute(object.AttributeFilePath, "dir/path.txt"))
+	require.Equal(t, "dir/path.txt", defaultArchiveName(obj, id))
+
+	obj.SetAttributes(attribute(object.AttributeFileName, "name.txt"), attribute(object.AttributeFilePath, "dir/"))
+	require.Equal(t, "name.txt", defaultArchiveName(obj, id))
+}
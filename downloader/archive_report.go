@@ -0,0 +1,103 @@
+package downloader
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+)
+
+// ArchiveErrorMode defines how objects that can't be added to a streamed
+// archive are reported to the client.
+type ArchiveErrorMode int32
+
+const (
+	// ArchiveErrorSkip skips failed objects, errors are only logged.
+	ArchiveErrorSkip ArchiveErrorMode = iota
+	// ArchiveErrorAbort breaks the connection on the first failed object, so
+	// that the client sees incomplete download.
+	ArchiveErrorAbort
+	// ArchiveErrorReport skips failed objects and appends the file listing them
+	// to the end of the archive.
+	ArchiveErrorReport
+)
+
+// archiveReportName is the name of the archive file listing failed objects.
+const archiveReportName = "ERRORS.txt"
+
+// ParseArchiveErrorMode parses archive error mode from its config value.
+func ParseArchiveErrorMode(s string) (ArchiveErrorMode, error) {
+	switch s {
+	case "", "skip":
+		return ArchiveErrorSkip, nil
+	case "abort":
+		return ArchiveErrorAbort, nil
+	case "report":
+		return ArchiveErrorReport, nil
+	default:
+		return ArchiveErrorSkip, fmt.Errorf("unknown archive error mode '%s'", s)
+	}
+}
+
+// archiveFailure describes the object that has not been added to archive.
+type archiveFailure struct {
+	id   *oid.ID
+	name string
+	err  error
+}
+
+// archiveReport returns the content of the archive file listing failed
+// objects, one per line.
+func archiveReport(failures []archiveFailure) []byte {
+	var buf bytes.Buffer
+	for _, f := range failures {
+		if f.id != nil {
+			buf.WriteString(f.id.EncodeToString())
+		} else {
+			buf.WriteString("-")
+		}
+		if f.name != "" {
+			fmt.Fprintf(&buf, " (%s)", f.name)
+		}
+		fmt.Fprintf(&buf, ": %v\n", f.err)
+	}
+	return buf.Bytes()
+}
+
+// writeArchiveReport adds the file listing failed objects to the archive.
+func writeArchiveReport(archWriter archiveWriter, failures []archiveFailure) error {
+	data := archiveReport(failures)
+
+	hdr := object.New()
+	hdr.SetPayloadSize(uint64(len(data)))
+
+	w, err := archWriter.createFile(hdr, archiveReportName)
+	if err != nil {
+		return fmt.Errorf("create archive file: %w", err)
+	}
+	if _, err = w.Write(data); err != nil {
+		return fmt.Errorf("write archive file: %w", err)
+	}
+
+	return nil
+}
+
+// errorTrackingWriter remembers the first error of the underlying writer, it
+// allows distinguishing client connection errors from object ones.
+type errorTrackingWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (w *errorTrackingWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	n, err := w.w.Write(p)
+	if err != nil {
+		w.err = err
+	}
+	return n, err
+}
@@ -0,0 +1,77 @@
+package downloader
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseArchiveErrorMode(t *testing.T) {
+	for s, mode := range map[string]ArchiveErrorMode{
+		"":       ArchiveErrorSkip,
+		"skip":   ArchiveErrorSkip,
+		"abort":  ArchiveErrorAbort,
+		"report": ArchiveErrorReport,
+	} {
+		res, err := ParseArchiveErrorMode(s)
+		require.NoError(t, err)
+		require.Equal(t, mode, res)
+	}
+
+	_, err := ParseArchiveErrorMode("unknown")
+	require.Error(t, err)
+}
+
+func TestSizedArchiveErrorMode(t *testing.T) {
+	for _, tc := range []struct {
+		mode       ArchiveErrorMode
+		overridden bool
+	}{
+		{mode: ArchiveErrorSkip, overridden: true},
+		{mode: ArchiveErrorReport, overridden: true},
+		{mode: ArchiveErrorAbort, overridden: false},
+	} {
+		mode, overridden := sizedArchiveErrorMode(tc.mode)
+		require.Equal(t, ArchiveErrorAbort, mode)
+		require.Equal(t, tc.overridden, overridden)
+	}
+}
+
+func TestWriteArchiveReport(t *testing.T) {
+	id := oidtest.ID()
+	failures := []archiveFailure{
+		{id: &id, name: "dir/file.txt", err: errors.New("object not found")},
+		{err: errors.New("search failed")},
+	}
+
+	var buf bytes.Buffer
+	aw := zipFormat(false).newWriter(&buf)
+	require.NoError(t, writeArchiveReport(aw, failures))
+	require.NoError(t, aw.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, zr.File, 1)
+	require.Equal(t, archiveReportName, zr.File[0].Name)
+
+	r, err := zr.File[0].Open()
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, id.EncodeToString()+" (dir/file.txt): object not found\n-: search failed\n", string(data))
+}
+
+func TestErrorTrackingWriter(t *testing.T) {
+	pr, pw := io.Pipe()
+	require.NoError(t, pr.Close())
+
+	w := &errorTrackingWriter{w: pw}
+	_, err := w.Write([]byte("data"))
+	require.ErrorIs(t, err, io.ErrClosedPipe)
+	require.ErrorIs(t, w.err, io.ErrClosedPipe)
+}
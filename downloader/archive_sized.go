@@ -0,0 +1,276 @@
+package downloader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// errRangeWritten is returned by rangeWriter when the whole range is written.
+var errRangeWritten = errors.New("range is written")
+
+// rangeWriter passes only the given range of written data to the underlying
+// writer.
+type rangeWriter struct {
+	w    io.Writer
+	skip uint64
+	left uint64
+}
+
+func (w *rangeWriter) Write(p []byte) (int, error) {
+	if w.left == 0 {
+		return 0, errRangeWritten
+	}
+
+	n := len(p)
+	if w.skip >= uint64(len(p)) {
+		w.skip -= uint64(len(p))
+		return n, nil
+	}
+	p = p[w.skip:]
+	w.skip = 0
+
+	if uint64(len(p)) > w.left {
+		p = p[:w.left]
+	}
+	written, err := w.w.Write(p)
+	w.left -= uint64(written)
+	if err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// sizedArchiveObject is the object of archive which size is calculated in
+// advance.
+type sizedArchiveObject struct {
+	id   oid.ID
+	name string
+	size uint64
+}
+
+// streamSizedArchive sends the archive with Content-Length and ETag headers,
+// it supports single range requests. Archive files are sorted by name, so that
+// the archive is the same for the same set of objects.
+func (d *Downloader) streamSizedArchive(c *fasthttp.RequestCtx, s archiveStream) {
+	log := s.log
+
+	objects, err := d.headArchiveObjects(s)
+	if err != nil {
+		if errors.Is(err, errArchiveObjectsLimit) {
+			log.Error("archive limits exceeded", zap.Error(err))
+			response.Error(c, err.Error(), fasthttp.StatusBadRequest)
+			return
+		}
+		log.Error("could not head archive objects", zap.Error(err))
+		if errors.Is(err, apistatus.ErrObjectNotFound) || errors.Is(err, apistatus.ErrObjectAlreadyRemoved) {
+			response.Error(c, "Not Found", fasthttp.StatusNotFound)
+			return
+		}
+		response.Error(c, "could not head archive objects: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	sort.Slice(objects, func(i, j int) bool {
+		if objects[i].name != objects[j].name {
+			return objects[i].name < objects[j].name
+		}
+		return objects[i].id.EncodeToString() < objects[j].id.EncodeToString()
+	})
+
+	if s.unique {
+		names := make(uniqueNames)
+		for i := range objects {
+			objects[i].name = names.unique(objects[i].name)
+		}
+		s.unique = false
+	}
+
+	var payloadSize uint64
+	files := make([]archiveFile, len(objects))
+	for i := range objects {
+		files[i] = archiveFile{name: objects[i].name, size: objects[i].size}
+		payloadSize += objects[i].size
+	}
+
+	if err = s.limits.check(uint64(len(objects)), payloadSize); err != nil {
+		log.Error("archive limits exceeded", zap.Error(err))
+		response.Error(c, err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	size, err := s.format.size(files)
+	if err != nil {
+		log.Error("could not calculate archive size", zap.Error(err))
+		response.Error(c, "could not calculate archive size: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	etag := sizedArchiveETag(objects)
+
+	c.Response.Header.Set(fasthttp.HeaderContentType, s.format.contentType)
+	c.Response.Header.Set(fasthttp.HeaderContentDisposition, s.contentDisposition(c))
+	c.Response.Header.Set(fasthttp.HeaderAcceptRanges, bytesUnit)
+	c.Response.Header.Set(fasthttp.HeaderETag, etag)
+
+	s.iterate = func(f func(oid.ID) bool) error {
+		for i := range objects {
+			if f(objects[i].id) {
+				break
+			}
+		}
+		return nil
+	}
+	s.name = func(i int, _ *object.Object) string {
+		return objects[i].name
+	}
+	if mode, overridden := sizedArchiveErrorMode(s.errorMode); overridden {
+		log.Debug("objects failed to be added to archive with Content-Length abort the connection",
+			zap.Int32("configured_mode", int32(s.errorMode)))
+		s.errorMode = mode
+	}
+
+	if rangeHeader := string(c.Request.Header.Peek(fasthttp.HeaderRange)); rangeHeader != "" {
+		ifRange := string(c.Request.Header.Peek(fasthttp.HeaderIfRange))
+		if ifRange != "" && !etagMatch(ifRange, etag, false) {
+			log.Debug("If-Range precondition failed, ignore Range header", zap.String("range", rangeHeader))
+		} else if ra, ok := d.archiveRange(c, log, rangeHeader, size); !ok {
+			return
+		} else if ra != nil {
+			s.ra = ra
+			c.Response.Header.Set(fasthttp.HeaderContentRange, ra.contentRange(size))
+			c.Response.SetStatusCode(fasthttp.StatusPartialContent)
+			c.Response.SetBodyStream(d.archiveBody(s), int(ra.length))
+			return
+		}
+	}
+
+	c.Response.SetStatusCode(http.StatusOK)
+	c.Response.SetBodyStream(d.archiveBody(s), int(size))
+}
+
+// sizedArchiveErrorMode returns the error mode of the archive which size is
+// sent before objects are read: failed objects can be neither skipped nor
+// reported then, so the connection is always aborted. It also returns true if
+// the given mode is overridden.
+func sizedArchiveErrorMode(mode ArchiveErrorMode) (ArchiveErrorMode, bool) {
+	return ArchiveErrorAbort, mode != ArchiveErrorAbort
+}
+
+// archiveRange parses Range header for the archive of the given size. It
+// returns nil range if the header has to be ignored and false if the request
+// has been already answered.
+func (d *Downloader) archiveRange(c *fasthttp.RequestCtx, log *zap.Logger, rangeHeader string, size uint64) (*httpRange, bool) {
+	ranges, err := parseRange(rangeHeader, size)
+	if errors.Is(err, errRangeNotSatisfiable) {
+		log.Debug("requested range is not satisfiable", zap.String("range", rangeHeader))
+		response.Error(c, "Range Not Satisfiable", fasthttp.StatusRequestedRangeNotSatisfiable)
+		c.Response.Header.Set(fasthttp.HeaderContentRange, bytesUnit+" */"+strconv.FormatUint(size, 10))
+		return nil, false
+	}
+	if err != nil {
+		log.Debug("ignore Range header", zap.String("range", rangeHeader), zap.Error(err))
+		return nil, true
+	}
+	if ranges = coalesceRanges(ranges); len(ranges) > 1 {
+		log.Debug("multiple ranges are not supported for archives, ignore Range header", zap.String("range", rangeHeader))
+		return nil, true
+	}
+
+	return &ranges[0], true
+}
+
+// headArchiveObjects collects objects passed by the archive stream iterate
+// function and heads them concurrently. Objects with invalid archive file
+// names are skipped. errArchiveObjectsLimit is returned without heading
+// objects if there are too many of them.
+func (d *Downloader) headArchiveObjects(s archiveStream) ([]sizedArchiveObject, error) {
+	var (
+		ids      []oid.ID
+		limitErr error
+	)
+	if err := s.iterate(func(id oid.ID) bool {
+		ids = append(ids, id)
+		// objects are counted before heading them
+		limitErr = s.limits.check(uint64(len(ids)), 0)
+		return limitErr != nil
+	}); err != nil {
+		return nil, fmt.Errorf("iterating over selected objects failed: %w", err)
+	}
+	if limitErr != nil {
+		return nil, limitErr
+	}
+
+	var prm client.PrmObjectHead
+	if s.btoken != nil {
+		prm.WithBearerToken(*s.btoken)
+	}
+
+	var (
+		wg      sync.WaitGroup
+		objects = make([]sizedArchiveObject, len(ids))
+		errs    = make([]error, len(ids))
+		slots   = make(chan struct{}, d.settings.ZipConcurrency())
+	)
+
+	for i := range ids {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			hdr, err := d.pool.ObjectHead(d.appCtx, s.cnrID, ids[i], d.signer, prm)
+			if err != nil {
+				errs[i] = fmt.Errorf("head object %s: %w", ids[i], err)
+				return
+			}
+			objects[i] = sizedArchiveObject{id: ids[i], name: s.name(i, hdr), size: hdr.PayloadSize()}
+		}(i)
+	}
+	wg.Wait()
+
+	res := objects[:0]
+	for i := range objects {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		if !isValidArchiveName(objects[i].name) {
+			s.log.Error("skip object with invalid archive file name", zap.Stringer("oid", objects[i].id),
+				zap.String("name", objects[i].name))
+			continue
+		}
+		res = append(res, objects[i])
+	}
+
+	return res, nil
+}
+
+// sizedArchiveETag returns entity tag of the archive, it depends on the
+// objects and their names only.
+func sizedArchiveETag(objects []sizedArchiveObject) string {
+	h := sha256.New()
+	for _, obj := range objects {
+		h.Write(obj.id[:])
+		h.Write([]byte(obj.name))
+		h.Write([]byte{0})
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)) + `"`
+}
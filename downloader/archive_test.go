@@ -0,0 +1,66 @@
+package downloader
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchiveNaming(t *testing.T) {
+	id := oidtest.ID()
+
+	newObject := func(attrs map[string]string) *object.Object {
+		obj := object.New()
+		obj.SetID(id)
+		var res []object.Attribute
+		for k, v := range attrs {
+			attr := object.NewAttribute()
+			attr.SetKey(k)
+			attr.SetValue(v)
+			res = append(res, *attr)
+		}
+		obj.SetAttributes(res...)
+		return obj
+	}
+
+	withPath := newObject(map[string]string{object.AttributeFilePath: "project/build/2024/app.bin"})
+	withName := newObject(map[string]string{object.AttributeFileName: "app.bin"})
+	noNames := newObject(nil)
+
+	for _, tc := range []struct {
+		name     string
+		naming   archiveNaming
+		obj      *object.Object
+		expected string
+	}{
+		{name: "file path", obj: withPath, expected: "project/build/2024/app.bin"},
+		{name: "no fallback", obj: withName, expected: ""},
+		{name: "strip prefix", naming: archiveNaming{stripDir: "project/build/"}, obj: withPath, expected: "2024/app.bin"},
+		{name: "strip other prefix", naming: archiveNaming{stripDir: "other/"}, obj: withPath, expected: "project/build/2024/app.bin"},
+		{name: "fallback to file name", naming: archiveNaming{fallback: true}, obj: withName, expected: "app.bin"},
+		{name: "fallback to id", naming: archiveNaming{fallback: true}, obj: noNames, expected: id.EncodeToString()},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, tc.naming.name(tc.obj))
+		})
+	}
+}
+
+func TestUniqueNames(t *testing.T) {
+	names := make(uniqueNames)
+
+	for _, tc := range [][2]string{
+		{"dir/file.txt", "dir/file.txt"},
+		{"dir/file.txt", "dir/file (1).txt"},
+		{"dir/file.txt", "dir/file (2).txt"},
+		{"dir/file (1).txt", "dir/file (1) (1).txt"},
+		{"dir.d/file", "dir.d/file"},
+		{"dir.d/file", "dir.d/file (1)"},
+		{".hidden", ".hidden"},
+		{".hidden", ".hidden (1)"},
+	} {
+		require.Equal(t, tc[1], names.unique(tc[0]))
+	}
+}
@@ -0,0 +1,154 @@
+package downloader
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/valyala/fasthttp"
+)
+
+// maxCacheAge is the maximum value of max-age directive recommended by
+// RFC 2616, it's also used as an upper bound for Expires header.
+const maxCacheAge = 365 * 24 * time.Hour
+
+// CachePolicy contains Cache-Control header values for object download
+// routes. Empty value means no Cache-Control header.
+type CachePolicy struct {
+	// ByAddress is used for /get/{cid}/{oid} route, object addressed this way
+	// never changes.
+	ByAddress string
+	// ByAttribute is used for /get_by_attribute route, the object found by
+	// attribute can be replaced with another one.
+	ByAttribute string
+}
+
+type cachePolicies struct {
+	global     CachePolicy
+	containers map[cid.ID]CachePolicy
+}
+
+// policy returns the policy for the container overriding empty fields with
+// global ones.
+func (p *cachePolicies) policy(cnrID cid.ID) CachePolicy {
+	res := p.global
+	if cnr, ok := p.containers[cnrID]; ok {
+		if cnr.ByAddress != "" {
+			res.ByAddress = cnr.ByAddress
+		}
+		if cnr.ByAttribute != "" {
+			res.ByAttribute = cnr.ByAttribute
+		}
+	}
+	return res
+}
+
+// objectExpirationEpoch returns the value of object expiration epoch attribute
+// if it's set and valid.
+func objectExpirationEpoch(obj *object.Object) (uint64, bool) {
+	for _, attr := range obj.Attributes() {
+		if attr.Key() != object.AttributeExpirationEpoch {
+			continue
+		}
+		epoch, err := strconv.ParseUint(attr.Value(), 10, 64)
+		return epoch, err == nil
+	}
+
+	return 0, false
+}
+
+// epochState contains network epoch parameters and the time they were
+// received at.
+type epochState struct {
+	durations utils.EpochDurations
+	updated   time.Time
+}
+
+// expirationTime calculates the time the object expires at, the object is
+// considered expired after the end of its expiration epoch, but the progress
+// of the current epoch is unknown, so the result is a lower bound counted from
+// the time epoch parameters were received at.
+func expirationTime(expEpoch uint64, state *epochState, now time.Time) time.Time {
+	if expEpoch <= state.durations.CurrentEpoch {
+		return now
+	}
+
+	left := expEpoch - state.durations.CurrentEpoch
+	epoch := state.durations.EpochDuration()
+	if epoch <= 0 || left > uint64(maxCacheAge/epoch) {
+		return now.Add(maxCacheAge)
+	}
+
+	expires := state.updated.Add(time.Duration(left) * epoch)
+	switch {
+	case expires.Before(now):
+		return now
+	case expires.After(now.Add(maxCacheAge)):
+		return now.Add(maxCacheAge)
+	default:
+		return expires
+	}
+}
+
+// capMaxAge limits max-age and s-maxage directives of Cache-Control value
+// with the given number of seconds.
+func capMaxAge(cacheControl string, maxAge int64) string {
+	directives := strings.Split(cacheControl, ",")
+	for i := range directives {
+		directives[i] = strings.TrimSpace(directives[i])
+		name, val, ok := strings.Cut(directives[i], "=")
+		if !ok || (!strings.EqualFold(name, "max-age") && !strings.EqualFold(name, "s-maxage")) {
+			continue
+		}
+		if age, err := strconv.ParseInt(val, 10, 64); err == nil && age <= maxAge {
+			continue
+		}
+		directives[i] = name + "=" + strconv.FormatInt(maxAge, 10)
+	}
+
+	return strings.Join(directives, ", ")
+}
+
+// makePrivate replaces public directive of Cache-Control value with private
+// one, so that shared caches don't store replies to authorized requests.
+func makePrivate(cacheControl string) string {
+	directives := strings.Split(cacheControl, ",")
+	for i := range directives {
+		directives[i] = strings.TrimSpace(directives[i])
+		if strings.EqualFold(directives[i], "public") {
+			directives[i] = "private"
+		}
+	}
+
+	return strings.Join(directives, ", ")
+}
+
+// setCacheHeaders sets Cache-Control header from the request policy and
+// Expires header calculated from the object expiration epoch.
+func (r request) setCacheHeaders(obj *object.Object) {
+	cacheControl := r.cacheControl
+	if cacheControl != "" && bearerToken(r.RequestCtx) != nil {
+		cacheControl = makePrivate(cacheControl)
+	}
+
+	if expEpoch, ok := objectExpirationEpoch(obj); ok {
+		if r.epochState == nil {
+			r.log.Debug("epoch durations are unknown, Expires header is not set")
+		} else {
+			now := time.Now()
+			expires := expirationTime(expEpoch, r.epochState, now)
+			r.Response.Header.Set(fasthttp.HeaderExpires, expires.UTC().Format(http.TimeFormat))
+			if cacheControl != "" {
+				cacheControl = capMaxAge(cacheControl, int64(expires.Sub(now)/time.Second))
+			}
+		}
+	}
+
+	if cacheControl != "" {
+		r.Response.Header.Set(fasthttp.HeaderCacheControl, cacheControl)
+	}
+}
@@ -0,0 +1,60 @@
+package downloader
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachePolicy(t *testing.T) {
+	var s Settings
+
+	cnr1, cnr2 := cidtest.ID(), cidtest.ID()
+	require.Equal(t, CachePolicy{}, s.CachePolicy(cnr1))
+
+	global := CachePolicy{ByAddress: "max-age=100", ByAttribute: "max-age=10"}
+	s.SetCachePolicies(global, map[cid.ID]CachePolicy{
+		cnr2: {ByAttribute: "no-cache"},
+	})
+
+	require.Equal(t, global, s.CachePolicy(cnr1))
+	require.Equal(t, CachePolicy{ByAddress: "max-age=100", ByAttribute: "no-cache"}, s.CachePolicy(cnr2))
+}
+
+func TestExpirationTime(t *testing.T) {
+	now := time.Now()
+	state := &epochState{
+		durations: utils.EpochDurations{
+			CurrentEpoch:  10,
+			MsPerBlock:    1000,
+			BlockPerEpoch: 60,
+		},
+		updated: now,
+	}
+
+	require.Equal(t, now, expirationTime(5, state, now))
+	require.Equal(t, now, expirationTime(10, state, now))
+	require.Equal(t, now.Add(2*time.Minute), expirationTime(12, state, now))
+	require.Equal(t, now.Add(maxCacheAge), expirationTime(1<<62, state, now))
+
+	// time passed since epoch parameters were received is taken into account
+	state.updated = now.Add(-time.Minute)
+	require.Equal(t, now.Add(time.Minute), expirationTime(12, state, now))
+	state.updated = now.Add(-time.Hour)
+	require.Equal(t, now, expirationTime(12, state, now))
+}
+
+func TestCapMaxAge(t *testing.T) {
+	require.Equal(t, "public, max-age=100, immutable", capMaxAge("public, max-age=31536000, immutable", 100))
+	require.Equal(t, "max-age=10, s-maxage=100", capMaxAge("max-age=10,s-maxage=1000", 100))
+	require.Equal(t, "no-cache", capMaxAge("no-cache", 100))
+}
+
+func TestMakePrivate(t *testing.T) {
+	require.Equal(t, "private, max-age=60", makePrivate("public, max-age=60"))
+	require.Equal(t, "no-store", makePrivate("no-store"))
+}
@@ -0,0 +1,201 @@
+package downloader
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// attributeContentEncoding is the object attribute containing the coding of
+// the payload, such objects are never compressed by the gateway.
+const attributeContentEncoding = "Content-Encoding"
+
+// encoder is a compressing writer which can be reused.
+type encoder interface {
+	io.WriteCloser
+	Reset(io.Writer)
+}
+
+// encoderPools contains pools of encoders of the supported content codings.
+var encoderPools = map[string]*sync.Pool{
+	"gzip": {New: func() any {
+		w, _ := gzip.NewWriterLevel(nil, gzip.DefaultCompression)
+		return w
+	}},
+	"br": {New: func() any {
+		return brotli.NewWriterLevel(nil, brotli.DefaultCompression)
+	}},
+	"zstd": {New: func() any {
+		w, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+		return w
+	}},
+}
+
+// Compression contains settings of on-the-fly compression of object
+// payloads.
+type Compression struct {
+	minSize   uint64
+	types     []string
+	encodings []string
+}
+
+// NewCompression creates compression settings. Payloads of the given media
+// types (type/* wildcards are allowed) not smaller than minSize are compressed
+// with one of the encodings accepted by the client, the encodings are listed
+// in order of preference.
+func NewCompression(minSize uint64, types, encodings []string) (*Compression, error) {
+	for _, enc := range encodings {
+		if _, ok := encoderPools[enc]; !ok {
+			return nil, fmt.Errorf("unsupported encoding '%s'", enc)
+		}
+	}
+
+	c := &Compression{
+		minSize:   minSize,
+		types:     make([]string, len(types)),
+		encodings: encodings,
+	}
+	for i := range types {
+		c.types[i] = strings.ToLower(strings.TrimSpace(types[i]))
+	}
+
+	return c, nil
+}
+
+// compressible checks whether the payload of the given type and size has to
+// be compressed.
+func (c *Compression) compressible(contentType string, size uint64) bool {
+	if size < c.minSize {
+		return false
+	}
+
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	for _, t := range c.types {
+		if t == mediaType || strings.HasSuffix(t, "/*") && strings.HasPrefix(mediaType, t[:len(t)-1]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// negotiateEncoding chooses the supported encoding with the highest quality
+// value in Accept-Encoding header, the order of supported encodings breaks
+// ties. Empty string means no encoding is acceptable.
+func negotiateEncoding(acceptEncoding string, supported []string) string {
+	var (
+		qs       = make(map[string]float64)
+		wildcard = -1.0
+	)
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if name == "x-gzip" {
+			name = "gzip"
+		}
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			key, val, _ := strings.Cut(param, "=")
+			if strings.TrimSpace(key) != "q" {
+				continue
+			}
+			if v, err := strconv.ParseFloat(strings.TrimSpace(val), 64); err == nil {
+				q = v
+			}
+		}
+
+		if name == "*" {
+			wildcard = q
+		} else {
+			qs[name] = q
+		}
+	}
+
+	var (
+		best  string
+		bestQ float64
+	)
+	for _, enc := range supported {
+		q, ok := qs[enc]
+		if !ok {
+			q = wildcard
+		}
+		if q > bestQ {
+			best, bestQ = enc, q
+		}
+	}
+
+	return best
+}
+
+// compressionEncoding returns the encoding the object payload is to be
+// compressed with, empty string means no compression.
+func (r request) compressionEncoding(obj *object.Object, contentType string) string {
+	if r.compression == nil || !r.compression.compressible(contentType, obj.PayloadSize()) {
+		return ""
+	}
+	for _, attr := range obj.Attributes() {
+		// it can be promoted to the response header regardless of the case
+		if strings.EqualFold(attr.Key(), attributeContentEncoding) {
+			return ""
+		}
+	}
+
+	r.Response.Header.Add(fasthttp.HeaderVary, fasthttp.HeaderAcceptEncoding)
+	return negotiateEncoding(string(r.Request.Header.Peek(fasthttp.HeaderAcceptEncoding)), r.compression.encodings)
+}
+
+// setCompressionHeaders sets Content-Encoding and weak ETag headers if the
+// object payload is to be compressed, it returns the encoding then. Both GET
+// and HEAD requests use it, so that their headers are the same.
+func (r request) setCompressionHeaders(obj *object.Object, contentType string) string {
+	encoding := r.compressionEncoding(obj, contentType)
+	if encoding != "" {
+		r.Response.Header.Set(fasthttp.HeaderContentEncoding, encoding)
+		// the compressed representation differs from the object payload
+		r.Response.Header.Set(fasthttp.HeaderETag, "W/"+objectETag(obj))
+	}
+	return encoding
+}
+
+// compressBody returns the reader of the payload compressed in background with
+// the given encoding, the payload is closed when it's read.
+func compressBody(log *zap.Logger, payload io.ReadCloser, encoding string) io.Reader {
+	pr, pw := io.Pipe()
+	go func() {
+		pool := encoderPools[encoding]
+		w := pool.Get().(encoder)
+		w.Reset(pw)
+
+		_, err := io.Copy(w, payload)
+		if closeErr := w.Close(); err == nil {
+			err = closeErr
+		}
+		pool.Put(w)
+
+		if closeErr := payload.Close(); closeErr != nil {
+			log.Debug("close object payload reader", zap.Error(closeErr))
+		}
+		if err != nil && !errors.Is(err, io.ErrClosedPipe) {
+			log.Error("could not compress object payload", zap.String("encoding", encoding), zap.Error(err))
+		}
+		_ = pw.CloseWithError(err)
+	}()
+
+	return pr
+}
@@ -0,0 +1,147 @@
+package downloader
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func TestNewCompression(t *testing.T) {
+	_, err := NewCompression(0, nil, []string{"gzip", "deflate"})
+	require.Error(t, err)
+
+	c, err := NewCompression(0, []string{" Text/* "}, []string{"gzip"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"text/*"}, c.types)
+}
+
+func TestCompressible(t *testing.T) {
+	c, err := NewCompression(100, []string{"text/*", "application/json"}, []string{"gzip"})
+	require.NoError(t, err)
+
+	require.True(t, c.compressible("text/plain; charset=utf-8", 100))
+	require.True(t, c.compressible("Application/JSON", 1000))
+	require.False(t, c.compressible("application/json", 99))
+	require.False(t, c.compressible("image/jpeg", 1000))
+	require.False(t, c.compressible("application/jsonx", 1000))
+	require.False(t, c.compressible("", 1000))
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	supported := []string{"zstd", "br", "gzip"}
+
+	for header, expected := range map[string]string{
+		"":                          "",
+		"identity":                  "",
+		"gzip":                      "gzip",
+		"x-gzip":                    "gzip",
+		"gzip, br":                  "br",
+		"gzip, deflate, br, zstd":   "zstd",
+		"br;q=0.5, gzip;q=0.8":      "gzip",
+		"br;q=0, gzip;q=0":          "",
+		"*":                         "zstd",
+		"*;q=0.1, gzip":             "gzip",
+		"*, zstd;q=0":               "br",
+		"GZIP ; q=1.0 , br ; q=0.9": "gzip",
+	} {
+		require.Equal(t, expected, negotiateEncoding(header, supported), header)
+	}
+}
+
+func TestCompressionEncoding(t *testing.T) {
+	compression, err := NewCompression(0, []string{"text/*"}, []string{"gzip"})
+	require.NoError(t, err)
+
+	obj := object.New()
+	obj.SetPayloadSize(10)
+
+	newRequest := func(acceptEncoding string) request {
+		var c fasthttp.RequestCtx
+		c.Request.Header.Set(fasthttp.HeaderAcceptEncoding, acceptEncoding)
+		return request{RequestCtx: &c, log: zap.NewNop(), compression: compression}
+	}
+
+	r := newRequest("gzip")
+	require.Equal(t, "gzip", r.compressionEncoding(obj, "text/plain"))
+	require.Equal(t, fasthttp.HeaderAcceptEncoding, string(r.Response.Header.Peek(fasthttp.HeaderVary)))
+
+	r = newRequest("br")
+	require.Empty(t, r.compressionEncoding(obj, "text/plain"))
+	require.Equal(t, fasthttp.HeaderAcceptEncoding, string(r.Response.Header.Peek(fasthttp.HeaderVary)))
+
+	r = newRequest("gzip")
+	require.Empty(t, r.compressionEncoding(obj, "image/png"))
+	require.Empty(t, r.Response.Header.Peek(fasthttp.HeaderVary))
+
+	for _, key := range []string{attributeContentEncoding, "content-encoding"} {
+		attr := object.NewAttribute()
+		attr.SetKey(key)
+		attr.SetValue("gzip")
+		obj.SetAttributes(*attr)
+		require.Empty(t, r.compressionEncoding(obj, "text/plain"), key)
+	}
+
+	r.compression = nil
+	require.Empty(t, r.compressionEncoding(obj, "text/plain"))
+}
+
+func TestSetCompressionHeaders(t *testing.T) {
+	compression, err := NewCompression(0, []string{"text/*"}, []string{"gzip"})
+	require.NoError(t, err)
+
+	obj := object.New()
+	obj.SetID(oidtest.ID())
+	obj.SetPayloadSize(10)
+
+	var c fasthttp.RequestCtx
+	c.Request.Header.Set(fasthttp.HeaderAcceptEncoding, "gzip")
+	r := request{RequestCtx: &c, log: zap.NewNop(), compression: compression}
+	r.Response.Header.Set(fasthttp.HeaderETag, objectETag(obj))
+
+	require.Empty(t, r.setCompressionHeaders(obj, "image/png"))
+	require.Empty(t, r.Response.Header.Peek(fasthttp.HeaderContentEncoding))
+	require.Equal(t, objectETag(obj), string(r.Response.Header.Peek(fasthttp.HeaderETag)))
+
+	require.Equal(t, "gzip", r.setCompressionHeaders(obj, "text/plain"))
+	require.Equal(t, "gzip", string(r.Response.Header.Peek(fasthttp.HeaderContentEncoding)))
+	require.Equal(t, fasthttp.HeaderAcceptEncoding, string(r.Response.Header.Peek(fasthttp.HeaderVary)))
+	require.Equal(t, "W/"+objectETag(obj), string(r.Response.Header.Peek(fasthttp.HeaderETag)))
+}
+
+func TestCompressBody(t *testing.T) {
+	payload := strings.Repeat("compressible text payload\n", 1000)
+
+	decoders := map[string]func(io.Reader) (io.Reader, error){
+		"gzip": func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+		"br":   func(r io.Reader) (io.Reader, error) { return brotli.NewReader(r), nil },
+		"zstd": func(r io.Reader) (io.Reader, error) { return zstd.NewReader(r) },
+	}
+
+	for encoding, newDecoder := range decoders {
+		t.Run(encoding, func(t *testing.T) {
+			// the second iteration uses the encoder from the pool
+			for i := 0; i < 2; i++ {
+				body := readCloser{strings.NewReader(payload), io.NopCloser(nil)}
+				compressed, err := io.ReadAll(compressBody(zap.NewNop(), body, encoding))
+				require.NoError(t, err)
+				require.Less(t, len(compressed), len(payload))
+
+				dec, err := newDecoder(bytes.NewReader(compressed))
+				require.NoError(t, err)
+				res, err := io.ReadAll(dec)
+				require.NoError(t, err)
+				require.Equal(t, payload, string(res))
+			}
+		})
+	}
+}
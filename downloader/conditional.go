@@ -0,0 +1,129 @@
+package downloader
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/valyala/fasthttp"
+)
+
+// objectETag returns strong entity tag of the object. Objects are immutable
+// and their IDs are derived from headers including payload checksum, so
+// object ID is a perfect validator.
+func objectETag(obj *object.Object) string {
+	objID, _ := obj.ID()
+	return `"` + objID.EncodeToString() + `"`
+}
+
+// objectTimestamp returns the value of object Timestamp attribute if it's
+// set and valid.
+func objectTimestamp(obj *object.Object) (time.Time, bool) {
+	for _, attr := range obj.Attributes() {
+		if attr.Key() != object.AttributeTimestamp {
+			continue
+		}
+		value, err := strconv.ParseInt(attr.Value(), 10, 64)
+		if err != nil {
+			return time.Time{}, false
+		}
+		return time.Unix(value, 0), true
+	}
+
+	return time.Time{}, false
+}
+
+// etagMatch checks whether the list of entity tags from If-Match or
+// If-None-Match header contains the given one. Weak comparison ignores
+// W/ prefix, strong one never matches weak tags.
+func etagMatch(list, etag string, weak bool) bool {
+	for _, tag := range strings.Split(list, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return true
+		}
+		if strings.HasPrefix(tag, "W/") {
+			if !weak {
+				continue
+			}
+			tag = tag[2:]
+		}
+		if tag == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseHTTPDate parses date from conditional request header.
+func parseHTTPDate(val []byte) (time.Time, bool) {
+	if len(val) == 0 {
+		return time.Time{}, false
+	}
+	t, err := http.ParseTime(string(val))
+	return t, err == nil
+}
+
+// checkPreconditions evaluates conditional request headers (RFC 7232) against
+// the object in the order defined by the RFC. It returns false if the request
+// has been already answered with 304 or 412 status code.
+func (r request) checkPreconditions(obj *object.Object) bool {
+	etag := objectETag(obj)
+	modified, hasModified := objectTimestamp(obj)
+
+	if ifMatch := r.Request.Header.Peek(fasthttp.HeaderIfMatch); len(ifMatch) != 0 {
+		if !etagMatch(string(ifMatch), etag, false) {
+			response.Error(r.RequestCtx, "Precondition Failed", fasthttp.StatusPreconditionFailed)
+			return false
+		}
+	} else if since, ok := parseHTTPDate(r.Request.Header.Peek(fasthttp.HeaderIfUnmodifiedSince)); ok && hasModified {
+		if modified.After(since) {
+			response.Error(r.RequestCtx, "Precondition Failed", fasthttp.StatusPreconditionFailed)
+			return false
+		}
+	}
+
+	if ifNoneMatch := r.Request.Header.Peek(fasthttp.HeaderIfNoneMatch); len(ifNoneMatch) != 0 {
+		if etagMatch(string(ifNoneMatch), etag, true) {
+			r.notModified()
+			return false
+		}
+	} else if since, ok := parseHTTPDate(r.Request.Header.Peek(fasthttp.HeaderIfModifiedSince)); ok && hasModified {
+		if !modified.After(since) {
+			r.notModified()
+			return false
+		}
+	}
+
+	return true
+}
+
+// notModified answers with 304 status code keeping already set object headers
+// but dropping representation-specific ones.
+func (r request) notModified() {
+	r.Response.Header.Del(fasthttp.HeaderContentLength)
+	r.Response.Header.Del(fasthttp.HeaderContentType)
+	r.Response.SetStatusCode(fasthttp.StatusNotModified)
+	r.Response.SkipBody = true
+}
+
+// checkIfRange evaluates If-Range header. It returns false if Range header
+// has to be ignored and the whole payload has to be sent.
+func (r request) checkIfRange(obj *object.Object) bool {
+	ifRange := r.Request.Header.Peek(fasthttp.HeaderIfRange)
+	if len(ifRange) == 0 {
+		return true
+	}
+
+	if ifRange[0] == '"' || strings.HasPrefix(string(ifRange), "W/") {
+		return etagMatch(string(ifRange), objectETag(obj), false)
+	}
+
+	modified, ok := objectTimestamp(obj)
+	since, okSince := parseHTTPDate(ifRange)
+	return ok && okSince && modified.Equal(since)
+}
@@ -0,0 +1,110 @@
+package downloader
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+func TestETagMatch(t *testing.T) {
+	const etag = `"abc"`
+
+	require.True(t, etagMatch(`"abc"`, etag, false))
+	require.True(t, etagMatch(`"xyz", "abc"`, etag, false))
+	require.True(t, etagMatch(`*`, etag, false))
+	require.True(t, etagMatch(`W/"abc"`, etag, true))
+	require.False(t, etagMatch(`W/"abc"`, etag, false))
+	require.False(t, etagMatch(`"xyz"`, etag, true))
+}
+
+func TestCheckPreconditions(t *testing.T) {
+	modified := time.Unix(1700000000, 0)
+
+	obj := object.New()
+	obj.SetID(oidtest.ID())
+	attr := object.NewAttribute()
+	attr.SetKey(object.AttributeTimestamp)
+	attr.SetValue(strconv.FormatInt(modified.Unix(), 10))
+	obj.SetAttributes(*attr)
+
+	etag := objectETag(obj)
+	before := modified.Add(-time.Hour).UTC().Format(http.TimeFormat)
+	after := modified.Add(time.Hour).UTC().Format(http.TimeFormat)
+
+	for _, tc := range []struct {
+		name    string
+		headers map[string]string
+		pass    bool
+		status  int
+	}{
+		{
+			name: "no conditions",
+			pass: true,
+		},
+		{
+			name:    "if-match",
+			headers: map[string]string{fasthttp.HeaderIfMatch: etag},
+			pass:    true,
+		},
+		{
+			name:    "if-match failed",
+			headers: map[string]string{fasthttp.HeaderIfMatch: `"other"`},
+			status:  fasthttp.StatusPreconditionFailed,
+		},
+		{
+			name:    "if-unmodified-since failed",
+			headers: map[string]string{fasthttp.HeaderIfUnmodifiedSince: before},
+			status:  fasthttp.StatusPreconditionFailed,
+		},
+		{
+			name:    "if-unmodified-since is ignored with if-match",
+			headers: map[string]string{fasthttp.HeaderIfMatch: "*", fasthttp.HeaderIfUnmodifiedSince: before},
+			pass:    true,
+		},
+		{
+			name:    "if-none-match",
+			headers: map[string]string{fasthttp.HeaderIfNoneMatch: etag},
+			status:  fasthttp.StatusNotModified,
+		},
+		{
+			name:    "if-none-match with other tag",
+			headers: map[string]string{fasthttp.HeaderIfNoneMatch: `"other"`},
+			pass:    true,
+		},
+		{
+			name:    "if-modified-since",
+			headers: map[string]string{fasthttp.HeaderIfModifiedSince: after},
+			status:  fasthttp.StatusNotModified,
+		},
+		{
+			name:    "if-modified-since is ignored with if-none-match",
+			headers: map[string]string{fasthttp.HeaderIfNoneMatch: `"other"`, fasthttp.HeaderIfModifiedSince: after},
+			pass:    true,
+		},
+		{
+			name:    "modified",
+			headers: map[string]string{fasthttp.HeaderIfModifiedSince: before},
+			pass:    true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var ctx fasthttp.RequestCtx
+			for k, v := range tc.headers {
+				ctx.Request.Header.Set(k, v)
+			}
+			r := request{RequestCtx: &ctx, log: zap.NewNop()}
+
+			require.Equal(t, tc.pass, r.checkPreconditions(obj))
+			if !tc.pass {
+				require.Equal(t, tc.status, ctx.Response.StatusCode())
+			}
+		})
+	}
+}
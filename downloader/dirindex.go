@@ -0,0 +1,222 @@
+package downloader
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// defaultIndexDocument is the object served for directory paths of
+// /{cid}/{path} route.
+const defaultIndexDocument = "index.html"
+
+// DefaultDirectoryIndexTemplate is the template of HTML directory index used
+// if no template file is configured.
+const DefaultDirectoryIndexTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Index of /{{.Path}}</title>
+<style>
+body { font-family: sans-serif; }
+td { padding: 0.2em 1em 0.2em 0; }
+td.size { text-align: right; }
+</style>
+</head>
+<body>
+<h1>Index of /{{.Path}}</h1>
+<table>
+<tr><th>Name</th><th>Size</th><th>Modified</th><th></th></tr>
+{{- if .Parent}}
+<tr><td><a href="{{.Parent}}">../</a></td><td></td><td></td><td></td></tr>
+{{- end}}
+{{- range .Directories}}
+<tr><td><a href="{{.Link}}">{{.Name}}</a></td><td></td><td></td><td></td></tr>
+{{- end}}
+{{- range .Files}}
+<tr><td><a href="{{.Link}}">{{.Name}}</a></td><td class="size">{{.Size}}</td><td>{{if not .ModTime.IsZero}}{{.ModTime.UTC.Format "2006-01-02 15:04:05"}}{{end}}</td><td><a href="{{.DownloadLink}}">download</a></td></tr>
+{{- end}}
+</table>
+{{- if .Next}}
+<p><a href="{{.Next}}">next page</a></p>
+{{- end}}
+</body>
+</html>
+`
+
+// DirectoryIndex contains settings of HTML directory index pages of
+// /{cid}/{path} route.
+type DirectoryIndex struct {
+	tmpl       *template.Template
+	global     bool
+	containers map[cid.ID]bool
+}
+
+// NewDirectoryIndex creates directory index settings with the given template
+// (DefaultDirectoryIndexTemplate is used if it's empty). Index pages are
+// enabled for all containers if global is true, containers override it.
+func NewDirectoryIndex(tmplText string, global bool, containers map[cid.ID]bool) (*DirectoryIndex, error) {
+	if tmplText == "" {
+		tmplText = DefaultDirectoryIndexTemplate
+	}
+
+	tmpl, err := template.New("index").Parse(tmplText)
+	if err != nil {
+		return nil, fmt.Errorf("parse template: %w", err)
+	}
+
+	return &DirectoryIndex{tmpl: tmpl, global: global, containers: containers}, nil
+}
+
+// enabled checks whether directory index pages are enabled for the container.
+func (x *DirectoryIndex) enabled(cnrID cid.ID) bool {
+	if x == nil {
+		return false
+	}
+	if enabled, ok := x.containers[cnrID]; ok {
+		return enabled
+	}
+	return x.global
+}
+
+// directoryIndexData is passed to the directory index template.
+type directoryIndexData struct {
+	// Container is the container ID or name from the request.
+	Container string
+	// Path is the directory path ending with '/' (empty for the root).
+	Path string
+	// Parent is the link to the parent directory, empty for the root.
+	Parent string
+	// Next is the link to the next page of the index, empty for the last one.
+	Next        string
+	Directories []directoryIndexDir
+	Files       []directoryIndexFile
+}
+
+type directoryIndexDir struct {
+	Name string
+	Link string
+}
+
+type directoryIndexFile struct {
+	Name         string
+	Link         string
+	DownloadLink string
+	ObjectID     string
+	Size         uint64
+	ContentType  string
+	ModTime      time.Time
+}
+
+// relativeLink returns the escaped link to the name relative to the
+// directory.
+func relativeLink(name string) string {
+	// ./ prefix prevents names with ':' from being parsed as schemes, '+' is
+	// escaped too since some clients and proxies treat it as a space
+	return "./" + strings.ReplaceAll((&url.URL{Path: name}).EscapedPath(), "+", "%2B")
+}
+
+// newDirectoryIndexData converts the object list page of the directory, the
+// page is expected to contain the newest objects of paths only.
+func newDirectoryIndexData(container, dir string, page listPage) directoryIndexData {
+	data := directoryIndexData{
+		Container:   container,
+		Path:        dir,
+		Directories: make([]directoryIndexDir, 0, len(page.Prefixes)),
+		Files:       make([]directoryIndexFile, 0, len(page.Objects)),
+	}
+	if dir != "" {
+		data.Parent = "../"
+	}
+	if page.NextCursor != "" {
+		data.Next = "./?cursor=" + url.QueryEscape(page.NextCursor)
+	}
+
+	for _, prefix := range page.Prefixes {
+		name := strings.TrimPrefix(prefix, dir)
+		data.Directories = append(data.Directories, directoryIndexDir{Name: name, Link: relativeLink(name)})
+	}
+
+	for i := range page.Objects {
+		entry := &page.Objects[i]
+		name := strings.TrimPrefix(entry.FilePath, dir)
+		file := directoryIndexFile{
+			Name:         name,
+			Link:         relativeLink(name),
+			DownloadLink: relativeLink(name) + "?download=true",
+			ObjectID:     entry.ObjectID,
+			Size:         entry.Size,
+			ContentType:  entry.ContentType,
+		}
+		if entry.Timestamp != nil {
+			file.ModTime = time.Unix(*entry.Timestamp, 0)
+		}
+		data.Files = append(data.Files, file)
+	}
+
+	return data
+}
+
+// serveDirectory serves the index document of the directory or its HTML
+// index page if it's enabled for the container.
+func (d *Downloader) serveDirectory(c *fasthttp.RequestCtx, log *zap.Logger, scid string, cnrID cid.ID, dir string,
+	mode ambiguityMode, f func(request, *pool.Pool, oid.Address, user.Signer)) {
+	ids, err := d.findVersions(c, &cnrID, object.AttributeFilePath, dir+defaultIndexDocument)
+	if err == nil {
+		d.serveVersion(c, log, cnrID, ids, mode, f)
+		return
+	}
+	if !errors.Is(err, errObjectNotFound) {
+		log.Error("could not find index document", zap.Error(err))
+		response.Error(c, err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	index := d.settings.DirectoryIndex()
+	if !index.enabled(cnrID) {
+		log.Debug("directory index is disabled for container")
+		response.Error(c, "object not found", fasthttp.StatusNotFound)
+		return
+	}
+
+	params := listParams{prefix: dir, delimiter: "/", limit: defaultListLimit, newest: true}
+	if params.cursor, err = parseListCursor(c.QueryArgs()); err != nil {
+		log.Error("invalid directory index cursor", zap.Error(err))
+		response.Error(c, err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	page, err := d.listObjects(c, log, cnrID, params)
+	if err != nil {
+		log.Error("could not list objects", zap.Error(err))
+		response.Error(c, "could not list objects: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+	if len(page.Objects) == 0 && len(page.Prefixes) == 0 && page.NextCursor == "" && params.cursor == "" && dir != "" {
+		response.Error(c, "object not found", fasthttp.StatusNotFound)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err = index.tmpl.Execute(&buf, newDirectoryIndexData(scid, dir, page)); err != nil {
+		log.Error("could not render directory index", zap.Error(err))
+		response.Error(c, "could not render directory index: "+err.Error(), fasthttp.StatusInternalServerError)
+		return
+	}
+
+	c.SetContentType("text/html; charset=utf-8")
+	c.SetBody(buf.Bytes())
+}
@@ -0,0 +1,161 @@
+package downloader
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/fasthttp/router"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestDirectoryIndexEnabled(t *testing.T) {
+	var index *DirectoryIndex
+	require.False(t, index.enabled(cidtest.ID()))
+
+	enabled, disabled := cidtest.ID(), cidtest.ID()
+	index, err := NewDirectoryIndex("", false, map[cid.ID]bool{enabled: true, disabled: false})
+	require.NoError(t, err)
+	require.True(t, index.enabled(enabled))
+	require.False(t, index.enabled(disabled))
+	require.False(t, index.enabled(cidtest.ID()))
+
+	index.global = true
+	require.False(t, index.enabled(disabled))
+	require.True(t, index.enabled(cidtest.ID()))
+
+	_, err = NewDirectoryIndex("{{.Unclosed", true, nil)
+	require.Error(t, err)
+}
+
+func TestDirectoryIndexData(t *testing.T) {
+	newEntry := func(filePath string, timestamp int64) *listEntry {
+		hdr := object.New()
+		hdr.SetPayloadSize(10)
+
+		var pathAttr, tsAttr object.Attribute
+		pathAttr.SetKey(object.AttributeFilePath)
+		pathAttr.SetValue(filePath)
+		tsAttr.SetKey(object.AttributeTimestamp)
+		tsAttr.SetValue(strconv.FormatInt(timestamp, 10))
+		hdr.SetAttributes(pathAttr, tsAttr)
+
+		return newListEntry(oidtest.ID(), hdr)
+	}
+
+	var (
+		oldVersion = newEntry("docs/a b.txt", 100)
+		newVersion = newEntry("docs/a b.txt", 200)
+		entries    = []*listEntry{
+			newEntry("docs/sub/c.txt", 100),
+			newVersion,
+			newEntry("docs/sub/d.txt", 100),
+			oldVersion,
+			newEntry("docs/x:y.txt", 100),
+		}
+	)
+
+	data := newDirectoryIndexData("site", "docs/", listPageOf(entries, listParams{prefix: "docs/", delimiter: "/", limit: defaultListLimit, newest: true}))
+	require.Equal(t, "../", data.Parent)
+	require.Empty(t, data.Next)
+	require.Equal(t, []directoryIndexDir{{Name: "sub/", Link: "./sub/"}}, data.Directories)
+	require.Len(t, data.Files, 2)
+	require.Equal(t, "a b.txt", data.Files[0].Name)
+	require.Equal(t, "./a%20b.txt", data.Files[0].Link)
+	require.Equal(t, "./a%20b.txt?download=true", data.Files[0].DownloadLink)
+	require.Equal(t, newVersion.ObjectID, data.Files[0].ObjectID)
+	require.EqualValues(t, 200, data.Files[0].ModTime.Unix())
+	require.Equal(t, "./x:y.txt", data.Files[1].Link)
+
+	// duplicates are removed before paging, so older versions and repeated
+	// subdirectories don't appear on other pages
+	var (
+		params = listParams{prefix: "docs/", delimiter: "/", limit: 1, newest: true}
+		files  []string
+		dirs   []string
+	)
+	for i := 0; ; i++ {
+		require.Less(t, i, len(entries))
+
+		page := listPageOf(entries, params)
+		pageData := newDirectoryIndexData("site", "docs/", page)
+		for _, f := range pageData.Files {
+			files = append(files, f.ObjectID)
+		}
+		for _, d := range pageData.Directories {
+			dirs = append(dirs, d.Name)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		var (
+			args fasthttp.Args
+			err  error
+		)
+		args.Set("cursor", page.NextCursor)
+		params.cursor, err = parseListCursor(&args)
+		require.NoError(t, err)
+	}
+	require.Equal(t, []string{newVersion.ObjectID, entries[4].ObjectID}, files)
+	require.Equal(t, []string{"sub/"}, dirs)
+
+	index, err := NewDirectoryIndex("", true, nil)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, index.tmpl.Execute(&buf, data))
+	require.Contains(t, buf.String(), `<a href="./sub/">sub/</a>`)
+	require.Contains(t, buf.String(), `<a href="./a%20b.txt">a b.txt</a>`)
+
+	require.NotContains(t, buf.String(), "next page")
+
+	page := listPageOf(entries, listParams{delimiter: "/", limit: defaultListLimit, newest: true})
+	page.NextCursor = entries[0].ObjectID
+	data = newDirectoryIndexData("site", "", page)
+	require.Empty(t, data.Parent)
+	require.Equal(t, "./?cursor="+entries[0].ObjectID, data.Next)
+	require.Equal(t, []directoryIndexDir{{Name: "docs/", Link: "./docs/"}}, data.Directories)
+	require.Empty(t, data.Files)
+
+	buf.Reset()
+	require.NoError(t, index.tmpl.Execute(&buf, data))
+	require.Contains(t, buf.String(), `<a href="./?cursor=`+entries[0].ObjectID+`">next page</a>`)
+}
+
+func TestRelativeLinkRoundTrip(t *testing.T) {
+	for _, name := range []string{
+		"a+b.txt",
+		"a b.txt",
+		"100%.txt",
+		"a+b c%d/",
+		"x:y.txt",
+		"кот.txt",
+	} {
+		link := relativeLink(name)
+		require.True(t, strings.HasPrefix(link, "./"), name)
+		require.NotContains(t, link, "+", name)
+
+		var (
+			c       fasthttp.RequestCtx
+			r       = router.New()
+			objPath string
+			err     error
+		)
+		r.GET("/{cid}/{path:*}", func(c *fasthttp.RequestCtx) {
+			objPath, _, err = objectPath(c)
+		})
+
+		c.Request.SetRequestURI("/cid/docs/" + strings.TrimPrefix(link, "./"))
+		c.Request.Header.SetMethod(fasthttp.MethodGet)
+		r.Handler(&c)
+
+		require.NoError(t, err, name)
+		require.Equal(t, "docs/"+name, objPath)
+	}
+}
@@ -0,0 +1,87 @@
+package downloader
+
+import (
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/valyala/fasthttp"
+)
+
+// fileNameArg is the query argument overriding the file name of the response.
+const fileNameArg = "filename"
+
+// defaultArchiveBaseName is the archive file name used when it can't be
+// derived from the request.
+const defaultArchiveBaseName = "archive"
+
+// contentDisposition returns Content-Disposition header value of the given
+// type with the file name encoded according to RFC 6266: quoted ASCII filename
+// parameter for legacy clients and RFC 5987 encoded filename* one.
+func contentDisposition(dispType, fileName string) string {
+	if fileName == "" {
+		return dispType
+	}
+
+	fileName = strings.ToValidUTF8(fileName, string(utf8.RuneError))
+
+	var sb strings.Builder
+	sb.WriteString(dispType)
+	sb.WriteString(`; filename="`)
+	for _, c := range fileName {
+		switch {
+		case c < ' ' || c > '~':
+			sb.WriteByte('_')
+		case c == '"' || c == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(c)
+		default:
+			sb.WriteRune(c)
+		}
+	}
+	sb.WriteString(`"; filename*=UTF-8''`)
+	for i := 0; i < len(fileName); i++ {
+		if isAttrChar(fileName[i]) {
+			sb.WriteByte(fileName[i])
+			continue
+		}
+		const hex = "0123456789ABCDEF"
+		sb.WriteByte('%')
+		sb.WriteByte(hex[fileName[i]>>4])
+		sb.WriteByte(hex[fileName[i]&0xf])
+	}
+
+	return sb.String()
+}
+
+// isAttrChar checks whether the byte can be used in RFC 5987 ext-value
+// without percent-encoding.
+func isAttrChar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", c) >= 0
+}
+
+// responseFileName returns the base name of the file to be used in
+// Content-Disposition header, it can be overridden by filename query argument.
+func responseFileName(c *fasthttp.RequestCtx, fileName string) string {
+	if override := c.QueryArgs().Peek(fileNameArg); len(override) != 0 {
+		fileName = string(override)
+	}
+	if fileName = path.Base(fileName); fileName == "." || fileName == "/" {
+		return ""
+	}
+	return fileName
+}
+
+// archiveFileName returns the name of the archive for the given object
+// prefix: the last element of the prefix with the archive extension.
+func archiveFileName(prefix, extension string) string {
+	name := path.Base(strings.TrimSuffix(prefix, "/"))
+	if name == "." || name == "/" {
+		name = defaultArchiveBaseName
+	}
+	return name + extension
+}
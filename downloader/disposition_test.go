@@ -0,0 +1,64 @@
+package downloader
+
+import (
+	"mime"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestContentDisposition(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		fileName string
+		expected string
+	}{
+		{name: "empty", expected: "inline"},
+		{name: "plain", fileName: "cat.jpeg", expected: `inline; filename="cat.jpeg"; filename*=UTF-8''cat.jpeg`},
+		{
+			name:     "special symbols",
+			fileName: `my "cat"; 100%.jpeg`,
+			expected: `inline; filename="my \"cat\"; 100%.jpeg"; filename*=UTF-8''my%20%22cat%22%3B%20100%25.jpeg`,
+		},
+		{
+			name:     "non-ascii",
+			fileName: "кот.jpeg",
+			expected: `inline; filename="___.jpeg"; filename*=UTF-8''%D0%BA%D0%BE%D1%82.jpeg`,
+		},
+		{name: "control", fileName: "a\nb", expected: `inline; filename="a_b"; filename*=UTF-8''a%0Ab`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			res := contentDisposition("inline", tc.fileName)
+			require.Equal(t, tc.expected, res)
+
+			if tc.fileName != "" {
+				_, params, err := mime.ParseMediaType(res)
+				require.NoError(t, err)
+				require.Equal(t, tc.fileName, params["filename"])
+			}
+		})
+	}
+}
+
+func TestResponseFileName(t *testing.T) {
+	var c fasthttp.RequestCtx
+	c.Request.SetRequestURI("/get/cid/oid")
+	require.Equal(t, "cat.jpeg", responseFileName(&c, "dir/cat.jpeg"))
+	require.Empty(t, responseFileName(&c, ""))
+
+	c.Request.SetRequestURI("/get/cid/oid?filename=" + "my%20cat.jpeg")
+	require.Equal(t, "my cat.jpeg", responseFileName(&c, "dir/cat.jpeg"))
+}
+
+func TestArchiveFileName(t *testing.T) {
+	for prefix, expected := range map[string]string{
+		"":                   "archive.zip",
+		"/":                  "archive.zip",
+		"common/prefix/":     "prefix.zip",
+		"common/prefix":      "prefix.zip",
+		"common/prefix/file": "file.zip",
+	} {
+		require.Equal(t, expected, archiveFileName(prefix, ".zip"), prefix)
+	}
+}
@@ -1,8 +1,6 @@
 package downloader
 
 import (
-	"archive/zip"
-	"bufio"
 	"bytes"
 	"context"
 	"errors"
@@ -10,7 +8,6 @@ import (
 	"io"
 	"net/http"
 	"net/url"
-	"path"
 	"strconv"
 	"strings"
 	"sync/atomic"
@@ -35,10 +32,19 @@ import (
 	"go.uber.org/zap"
 )
 
+// errObjectNotFound is returned when there is no object matching the search.
+var errObjectNotFound = errors.New("object not found")
+
 type request struct {
 	*fasthttp.RequestCtx
-	appCtx context.Context
-	log    *zap.Logger
+	appCtx       context.Context
+	log          *zap.Logger
+	cacheControl string
+	compression  *Compression
+	epochState   *epochState
+	// attributeHeaders maps lowercase attribute keys to the names of response
+	// headers they are promoted to.
+	attributeHeaders map[string]string
 }
 
 func isValidToken(s string) bool {
@@ -53,6 +59,17 @@ func isValidToken(s string) bool {
 	return true
 }
 
+// isValidHeaderValue checks whether the string can be sent as a value of
+// standard header, unlike isValidValue it allows quotes.
+func isValidHeaderValue(s string) bool {
+	for _, c := range s {
+		if (c < ' ' && c != '\t') || c > '~' {
+			return false
+		}
+	}
+	return true
+}
+
 func isValidValue(s string) bool {
 	for _, c := range s {
 		// HTTP specification allows for more technically, but we don't want to escape things.
@@ -94,10 +111,8 @@ func readContentType(maxSize uint64, rInit func(uint64) (io.Reader, error)) (str
 
 func (r request) receiveFile(clnt *pool.Pool, objectAddress oid.Address, signer user.Signer) {
 	var (
-		err      error
-		dis      = "inline"
-		start    = time.Now()
-		filename string
+		err   error
+		start = time.Now()
 	)
 	if err = tokens.StoreBearerToken(r.RequestCtx); err != nil {
 		r.log.Error("could not fetch and store bearer token", zap.Error(err))
@@ -105,8 +120,16 @@ func (r request) receiveFile(clnt *pool.Pool, objectAddress oid.Address, signer
 		return
 	}
 
+	btoken := bearerToken(r.RequestCtx)
+
+	if rangeHeader := r.Request.Header.Peek(fasthttp.HeaderRange); len(rangeHeader) != 0 {
+		if r.receiveRange(clnt, objectAddress, signer, btoken, string(rangeHeader), start) {
+			return
+		}
+	}
+
 	var prm client.PrmObjectGet
-	if btoken := bearerToken(r.RequestCtx); btoken != nil {
+	if btoken != nil {
 		prm.WithBearerToken(*btoken)
 	}
 
@@ -119,45 +142,17 @@ func (r request) receiveFile(clnt *pool.Pool, objectAddress oid.Address, signer
 
 	// we can't close reader in this function, so how to do it?
 
-	if r.Request.URI().QueryArgs().GetBool("download") {
-		dis = "attachment"
-	}
-
 	payloadSize := hdr.PayloadSize()
 
 	r.Response.Header.Set(fasthttp.HeaderContentLength, strconv.FormatUint(payloadSize, 10))
-	var contentType string
-	for _, attr := range hdr.Attributes() {
-		key := attr.Key()
-		val := attr.Value()
-		if !isValidToken(key) || !isValidValue(val) {
-			continue
-		}
-		if strings.HasPrefix(key, utils.SystemAttributePrefix) {
-			key = systemBackwardTranslator(key)
-		}
-		r.Response.Header.Set(utils.UserAttributeHeaderPrefix+key, val)
-		switch key {
-		case object.AttributeFileName:
-			filename = val
-		case object.AttributeTimestamp:
-			value, err := strconv.ParseInt(val, 10, 64)
-			if err != nil {
-				r.log.Info("couldn't parse creation date",
-					zap.String("key", key),
-					zap.String("val", val),
-					zap.Error(err))
-				continue
-			}
-			r.Response.Header.Set(fasthttp.HeaderLastModified,
-				time.Unix(value, 0).UTC().Format(http.TimeFormat))
-		case object.AttributeContentType:
-			contentType = val
+	filename, contentType := r.setObjectHeaders(clnt, &hdr)
+	if !r.checkPreconditions(&hdr) {
+		if err = payload.Close(); err != nil {
+			r.log.Debug("close object payload reader", zap.Error(err))
 		}
+		return
 	}
 
-	idsToResponse(&r.Response, &hdr)
-
 	if len(contentType) == 0 {
 		// determine the Content-Type from the payload head
 		var payloadHead []byte
@@ -184,11 +179,32 @@ func (r request) receiveFile(clnt *pool.Pool, objectAddress oid.Address, signer
 	}
 	r.SetContentType(contentType)
 
-	r.Response.Header.Set(fasthttp.HeaderContentDisposition, dis+"; filename="+path.Base(filename))
+	r.setContentDisposition(filename)
+
+	if encoding := r.setCompressionHeaders(&hdr, contentType); encoding != "" {
+		r.Response.SetBodyStream(compressBody(r.log, payload, encoding), -1)
+		return
+	}
 
 	r.Response.SetBodyStream(payload, int(payloadSize))
 }
 
+// setContentDisposition sets Content-Disposition header using the given file
+// name, the disposition type depends on the download query argument.
+func (r request) setContentDisposition(filename string) {
+	args := r.Request.URI().QueryArgs()
+	if len(r.Response.Header.Peek(fasthttp.HeaderContentDisposition)) != 0 && !args.Has("download") && !args.Has(fileNameArg) {
+		return // promoted from the object attribute
+	}
+
+	dis := "inline"
+	if args.GetBool("download") {
+		dis = "attachment"
+	}
+
+	r.Response.Header.Set(fasthttp.HeaderContentDisposition, contentDisposition(dis, responseFileName(r.RequestCtx, filename)))
+}
+
 // systemBackwardTranslator is used to convert headers looking like '__NEOFS__ATTR_NAME' to 'Neofs-Attr-Name'.
 func systemBackwardTranslator(key string) string {
 	// trim specified prefix '__NEOFS__'
@@ -252,11 +268,35 @@ type Downloader struct {
 	containerResolver resolver.Resolver
 	settings          *Settings
 	signer            user.Signer
+	metrics           Metrics
+	archiveStreams    atomic.Int32
+	hostCache         hostCache
+	listCache         listCache
+	websiteCache      websiteCache
+}
+
+// Metrics collects statistics of download requests.
+type Metrics interface {
+	// ArchiveCompleted is called when an archive stream is finished with the
+	// number of added and failed objects.
+	ArchiveCompleted(added, failed int, aborted bool)
 }
 
 // Settings stores reloading parameters, so it has to provide atomic getters and setters.
 type Settings struct {
-	zipCompression atomic.Bool
+	zipCompression  atomic.Bool
+	zipConcurrency  atomic.Int32
+	zipMemoryBudget atomic.Uint64
+	zipErrorMode    atomic.Int32
+	zipSized        atomic.Bool
+	cachePolicies   atomic.Pointer[cachePolicies]
+	archiveLimits   atomic.Pointer[ArchiveLimits]
+	compression     atomic.Pointer[Compression]
+	attrHeaders     atomic.Pointer[map[string]string]
+	websites        atomic.Pointer[websites]
+	virtualHosts    atomic.Pointer[VirtualHosts]
+	dirIndex        atomic.Pointer[DirectoryIndex]
+	epochState      atomic.Pointer[epochState]
 }
 
 func (s *Settings) ZipCompression() bool {
@@ -267,8 +307,154 @@ func (s *Settings) SetZipCompression(val bool) {
 	s.zipCompression.Store(val)
 }
 
+// ZipConcurrency returns the number of objects fetched concurrently for
+// archives.
+func (s *Settings) ZipConcurrency() int {
+	if val := s.zipConcurrency.Load(); val > 0 {
+		return int(val)
+	}
+	return 1
+}
+
+func (s *Settings) SetZipConcurrency(val int32) {
+	s.zipConcurrency.Store(val)
+}
+
+// ZipMemoryBudget returns the maximum total size of object payloads buffered
+// while fetching objects for a single archive.
+func (s *Settings) ZipMemoryBudget() uint64 {
+	return s.zipMemoryBudget.Load()
+}
+
+func (s *Settings) SetZipMemoryBudget(val uint64) {
+	s.zipMemoryBudget.Store(val)
+}
+
+// ZipContentLength returns true if the size of uncompressed zip archives is
+// calculated before streaming.
+func (s *Settings) ZipContentLength() bool {
+	return s.zipSized.Load()
+}
+
+func (s *Settings) SetZipContentLength(val bool) {
+	s.zipSized.Store(val)
+}
+
+// ZipErrorMode returns the way objects failed to be added to archive are
+// reported. It's not used for zip archives with Content-Length (see
+// ZipContentLength), they always abort the connection.
+func (s *Settings) ZipErrorMode() ArchiveErrorMode {
+	return ArchiveErrorMode(s.zipErrorMode.Load())
+}
+
+func (s *Settings) SetZipErrorMode(val ArchiveErrorMode) {
+	s.zipErrorMode.Store(int32(val))
+}
+
+// ArchiveLimits returns the limits of archive requests.
+func (s *Settings) ArchiveLimits() ArchiveLimits {
+	if l := s.archiveLimits.Load(); l != nil {
+		return *l
+	}
+	return ArchiveLimits{}
+}
+
+func (s *Settings) SetArchiveLimits(val ArchiveLimits) {
+	s.archiveLimits.Store(&val)
+}
+
+// Compression returns settings of object payload compression, nil means it's
+// disabled.
+func (s *Settings) Compression() *Compression {
+	return s.compression.Load()
+}
+
+func (s *Settings) SetCompression(val *Compression) {
+	s.compression.Store(val)
+}
+
+// AttributeHeaders returns the map of lowercase object attribute keys to the
+// names of response headers the attributes are promoted to.
+func (s *Settings) AttributeHeaders() map[string]string {
+	if m := s.attrHeaders.Load(); m != nil {
+		return *m
+	}
+	return nil
+}
+
+// SetAttributeHeaders sets the names of object attributes promoted to response
+// headers with the same names.
+func (s *Settings) SetAttributeHeaders(names []string) {
+	m := make(map[string]string, len(names))
+	for _, name := range names {
+		m[strings.ToLower(name)] = http.CanonicalHeaderKey(name)
+	}
+	s.attrHeaders.Store(&m)
+}
+
+// Website returns website settings of the container and true if website mode
+// is enabled for the container in the configuration, global settings are
+// returned otherwise.
+func (s *Settings) Website(cnrID cid.ID) (WebsiteConfig, bool) {
+	sites := s.websites.Load()
+	if sites == nil {
+		return WebsiteConfig{}, false
+	}
+	if cfg, ok := sites.containers[cnrID]; ok {
+		return cfg, true
+	}
+	return sites.global, false
+}
+
+// SetWebsites sets global website settings used for containers with website
+// mode enabled by the attribute and settings of containers with website mode
+// enabled in the configuration.
+func (s *Settings) SetWebsites(global WebsiteConfig, containers map[cid.ID]WebsiteConfig) {
+	s.websites.Store(&websites{global: global, containers: containers})
+}
+
+// VirtualHosts returns settings of container selection by Host header, nil
+// means virtual hosts are disabled.
+func (s *Settings) VirtualHosts() *VirtualHosts {
+	return s.virtualHosts.Load()
+}
+
+func (s *Settings) SetVirtualHosts(val *VirtualHosts) {
+	s.virtualHosts.Store(val)
+}
+
+// DirectoryIndex returns settings of HTML directory index pages, nil means
+// they are disabled.
+func (s *Settings) DirectoryIndex() *DirectoryIndex {
+	return s.dirIndex.Load()
+}
+
+func (s *Settings) SetDirectoryIndex(val *DirectoryIndex) {
+	s.dirIndex.Store(val)
+}
+
+// SetEpochDurations sets network epoch parameters used to calculate object
+// expiration time, they are considered to be received at the moment.
+func (s *Settings) SetEpochDurations(val utils.EpochDurations) {
+	s.epochState.Store(&epochState{durations: val, updated: time.Now()})
+}
+
+// CachePolicy returns Cache-Control policy for the container.
+func (s *Settings) CachePolicy(cnrID cid.ID) CachePolicy {
+	if p := s.cachePolicies.Load(); p != nil {
+		return p.policy(cnrID)
+	}
+	return CachePolicy{}
+}
+
+// SetCachePolicies sets global Cache-Control policy and per-container
+// overrides of it.
+func (s *Settings) SetCachePolicies(global CachePolicy, containers map[cid.ID]CachePolicy) {
+	s.cachePolicies.Store(&cachePolicies{global: global, containers: containers})
+}
+
 // New creates an instance of Downloader using specified options.
-func New(ctx context.Context, params *utils.AppParams, settings *Settings, signer user.Signer) *Downloader {
+func New(ctx context.Context, params *utils.AppParams, settings *Settings, signer user.Signer, metrics Metrics) *Downloader {
 	return &Downloader{
 		appCtx:            ctx,
 		log:               params.Logger,
@@ -276,14 +462,18 @@ func New(ctx context.Context, params *utils.AppParams, settings *Settings, signe
 		settings:          settings,
 		containerResolver: params.Resolver,
 		signer:            signer,
+		metrics:           metrics,
 	}
 }
 
 func (d *Downloader) newRequest(ctx *fasthttp.RequestCtx, log *zap.Logger) *request {
 	return &request{
-		RequestCtx: ctx,
-		appCtx:     d.appCtx,
-		log:        log,
+		RequestCtx:       ctx,
+		appCtx:           d.appCtx,
+		log:              log,
+		compression:      d.settings.Compression(),
+		epochState:       d.settings.epochState.Load(),
+		attributeHeaders: d.settings.AttributeHeaders(),
 	}
 }
 
@@ -319,7 +509,10 @@ func (d *Downloader) byAddress(c *fasthttp.RequestCtx, f func(request, *pool.Poo
 	addr.SetContainer(*cnrID)
 	addr.SetObject(*objID)
 
-	f(*d.newRequest(c, log), d.pool, addr, d.signer)
+	req := d.newRequest(c, log)
+	req.cacheControl = d.settings.CachePolicy(*cnrID).ByAddress
+
+	f(*req, d.pool, addr, d.signer)
 }
 
 // DownloadByAttribute handles attribute-based download requests.
@@ -336,88 +529,24 @@ func (d *Downloader) byAttribute(c *fasthttp.RequestCtx, f func(request, *pool.P
 		log     = d.log.With(zap.String("cid", scid), zap.String("attr_key", key), zap.String("attr_val", val))
 	)
 
-	containerID, err := utils.GetContainerID(d.appCtx, scid, d.containerResolver)
+	andFilters, err := andFiltersFromQuery(c.QueryArgs())
 	if err != nil {
-		log.Error("wrong container id", zap.Error(err))
-		response.Error(c, "wrong container id", fasthttp.StatusBadRequest)
+		log.Error("invalid search filter", zap.Error(err))
+		response.Error(c, "invalid search filter: "+err.Error(), fasthttp.StatusBadRequest)
 		return
 	}
 
-	res, err := d.search(c, containerID, key, val, object.MatchStringEqual)
-	if err != nil {
-		log.Error("could not search for objects", zap.Error(err))
-		response.Error(c, "could not search for objects: "+err.Error(), fasthttp.StatusBadRequest)
-		return
+	// combinations of attributes are expected to identify a single object
+	mode := ambiguityNewest
+	if len(andFilters) != 0 {
+		mode = ambiguityFail
 	}
-
-	defer res.Close()
-
-	buf := make([]oid.ID, 1)
-
-	n, _ := res.Read(buf)
-	if n == 0 {
-		err = res.Close()
-
-		if err == nil || errors.Is(err, io.EOF) {
-			log.Error("object not found", zap.Error(err))
-			response.Error(c, "object not found", fasthttp.StatusNotFound)
-			return
-		}
-
-		log.Error("read object list failed", zap.Error(err))
-		response.Error(c, "read object list failed: "+err.Error(), fasthttp.StatusBadRequest)
+	if mode, err = ambiguityFromQuery(c.QueryArgs(), mode); err != nil {
+		log.Error("invalid ambiguity mode", zap.Error(err))
+		response.Error(c, err.Error(), fasthttp.StatusBadRequest)
 		return
 	}
 
-	var addrObj oid.Address
-	addrObj.SetContainer(*containerID)
-	addrObj.SetObject(buf[0])
-
-	f(*d.newRequest(c, log), d.pool, addrObj, d.signer)
-}
-
-func (d *Downloader) search(c *fasthttp.RequestCtx, cid *cid.ID, key, val string, op object.SearchMatchType) (*client.ObjectListReader, error) {
-	filters := object.NewSearchFilters()
-	filters.AddRootFilter()
-	filters.AddFilter(key, val, op)
-
-	var prm client.PrmObjectSearch
-	prm.SetFilters(filters)
-	if btoken := bearerToken(c); btoken != nil {
-		prm.WithBearerToken(*btoken)
-	}
-
-	return d.pool.ObjectSearchInit(d.appCtx, *cid, d.signer, prm)
-}
-
-func (d *Downloader) getContainer(cnrID cid.ID) (container.Container, error) {
-	return d.pool.ContainerGet(d.appCtx, cnrID, client.PrmContainerGet{})
-}
-
-func (d *Downloader) addObjectToZip(zw *zip.Writer, obj *object.Object) (io.Writer, error) {
-	method := zip.Store
-	if d.settings.ZipCompression() {
-		method = zip.Deflate
-	}
-
-	filePath := getZipFilePath(obj)
-	if len(filePath) == 0 || filePath[len(filePath)-1] == '/' {
-		return nil, fmt.Errorf("invalid filepath '%s'", filePath)
-	}
-
-	return zw.CreateHeader(&zip.FileHeader{
-		Name:     filePath,
-		Method:   method,
-		Modified: time.Now(),
-	})
-}
-
-// DownloadZipped handles zip by prefix requests.
-func (d *Downloader) DownloadZipped(c *fasthttp.RequestCtx) {
-	scid, _ := c.UserValue("cid").(string)
-	prefix, _ := url.QueryUnescape(c.UserValue("prefix").(string))
-	log := d.log.With(zap.String("cid", scid), zap.String("prefix", prefix))
-
 	containerID, err := utils.GetContainerID(d.appCtx, scid, d.containerResolver)
 	if err != nil {
 		log.Error("wrong container id", zap.Error(err))
@@ -425,113 +554,47 @@ func (d *Downloader) DownloadZipped(c *fasthttp.RequestCtx) {
 		return
 	}
 
-	if err = tokens.StoreBearerToken(c); err != nil {
-		log.Error("could not fetch and store bearer token", zap.Error(err))
-		response.Error(c, "could not fetch and store bearer token: "+err.Error(), fasthttp.StatusBadRequest)
-		return
-	}
+	filters := object.NewSearchFilters()
+	filters.AddFilter(key, val, object.MatchStringEqual)
+	filters = append(filters, andFilters...)
 
-	// check if container exists here to be able to return 404 error,
-	// otherwise we get this error only in object iteration step
-	// and client get 200 OK.
-	if _, err = d.getContainer(*containerID); err != nil {
-		log.Error("could not check container existence", zap.Error(err))
-		if errors.Is(err, apistatus.ErrContainerNotFound) {
-			response.Error(c, "Not Found", fasthttp.StatusNotFound)
+	ids, err := d.findMatchingVersions(c, containerID, filters)
+	if err != nil {
+		log.Error("could not find object", zap.Error(err))
+		if errors.Is(err, errObjectNotFound) {
+			response.Error(c, "object not found", fasthttp.StatusNotFound)
 			return
 		}
-		response.Error(c, "could not check container existence: "+err.Error(), fasthttp.StatusBadRequest)
-		return
-	}
-
-	resSearch, err := d.search(c, containerID, object.AttributeFilePath, prefix, object.MatchCommonPrefix)
-	if err != nil {
-		log.Error("could not search for objects", zap.Error(err))
-		response.Error(c, "could not search for objects: "+err.Error(), fasthttp.StatusBadRequest)
+		response.Error(c, err.Error(), fasthttp.StatusBadRequest)
 		return
 	}
 
-	c.Response.Header.Set(fasthttp.HeaderContentType, "application/zip")
-	c.Response.Header.Set(fasthttp.HeaderContentDisposition, "attachment; filename=\"archive.zip\"")
-	c.Response.SetStatusCode(http.StatusOK)
-
-	c.SetBodyStreamWriter(func(w *bufio.Writer) {
-		defer resSearch.Close()
-
-		zipWriter := zip.NewWriter(w)
-
-		var bufZip []byte
-		var addr oid.Address
-
-		empty := true
-		called := false
-		btoken := bearerToken(c)
-		addr.SetContainer(*containerID)
-
-		errIter := resSearch.Iterate(func(id oid.ID) bool {
-			called = true
-
-			if empty {
-				bufZip = make([]byte, 3<<20) // the same as for upload
-			}
-			empty = false
-
-			addr.SetObject(id)
-			if err = d.zipObject(zipWriter, addr, btoken, bufZip); err != nil {
-				log.Error("failed to add object to archive", zap.String("oid", id.EncodeToString()), zap.Error(err))
-			}
-
-			return false
-		})
-		if errIter != nil {
-			log.Error("iterating over selected objects failed", zap.Error(errIter))
-		} else if !called {
-			log.Error("objects not found")
-		}
-
-		if err = zipWriter.Close(); err != nil {
-			log.Error("close zip writer", zap.Error(err))
-		}
-	})
+	d.serveVersion(c, log, *containerID, ids, mode, f)
 }
 
-func (d *Downloader) zipObject(zipWriter *zip.Writer, addr oid.Address, btoken *bearer.Token, bufZip []byte) error {
-	var prm client.PrmObjectGet
-	if btoken != nil {
-		prm.WithBearerToken(*btoken)
-	}
-
-	resGet, payloadReader, err := d.pool.ObjectGetInit(d.appCtx, addr.Container(), addr.Object(), d.signer, prm)
-	if err != nil {
-		return fmt.Errorf("get NeoFS object: %v", err)
-	}
-
-	objWriter, err := d.addObjectToZip(zipWriter, &resGet)
+// findObject returns the ID of the newest object with the attribute equal to
+// the given value, errObjectNotFound is returned if there is no such object.
+func (d *Downloader) findObject(c *fasthttp.RequestCtx, cnrID *cid.ID, key, val string) (oid.ID, error) {
+	ids, err := d.findVersions(c, cnrID, key, val)
 	if err != nil {
-		return fmt.Errorf("zip create header: %v", err)
-	}
-
-	if _, err = io.CopyBuffer(objWriter, payloadReader, bufZip); err != nil {
-		return fmt.Errorf("copy object payload to zip file: %v", err)
+		return oid.ID{}, err
 	}
+	return d.latestVersion(c, *cnrID, ids)
+}
 
-	if err = payloadReader.Close(); err != nil {
-		return fmt.Errorf("object body close error: %w", err)
-	}
+// searchObjects searches for root objects matching the filters.
+func (d *Downloader) searchObjects(c *fasthttp.RequestCtx, cid *cid.ID, filters object.SearchFilters) (*client.ObjectListReader, error) {
+	filters.AddRootFilter()
 
-	if err = zipWriter.Flush(); err != nil {
-		return fmt.Errorf("flush zip writer: %v", err)
+	var prm client.PrmObjectSearch
+	prm.SetFilters(filters)
+	if btoken := bearerToken(c); btoken != nil {
+		prm.WithBearerToken(*btoken)
 	}
 
-	return nil
+	return d.pool.ObjectSearchInit(d.appCtx, *cid, d.signer, prm)
 }
 
-func getZipFilePath(obj *object.Object) string {
-	for _, attr := range obj.Attributes() {
-		if attr.Key() == object.AttributeFilePath {
-			return attr.Value()
-		}
-	}
-
-	return ""
+func (d *Downloader) getContainer(cnrID cid.ID) (container.Container, error) {
+	return d.pool.ContainerGet(d.appCtx, cnrID, client.PrmContainerGet{})
 }
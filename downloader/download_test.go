@@ -3,7 +3,13 @@ package downloader
 import (
 	"testing"
 
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	usertest "github.com/nspcc-dev/neofs-sdk-go/user/test"
 	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
 )
 
 func TestSystemBackwardTranslator(t *testing.T) {
@@ -21,3 +27,53 @@ func TestSystemBackwardTranslator(t *testing.T) {
 		require.Equal(t, expected[i], res)
 	}
 }
+
+func TestAttributeHeaders(t *testing.T) {
+	var settings Settings
+	settings.SetAttributeHeaders([]string{"content-encoding", "Cache-Control", "Content-Disposition"})
+
+	obj := object.New()
+	obj.SetID(oidtest.ID())
+	obj.SetContainerID(cidtest.ID())
+	owner := usertest.ID(t)
+	obj.SetOwnerID(&owner)
+
+	var attrs []object.Attribute
+	for k, v := range map[string]string{
+		"Content-Encoding":    "gzip",
+		"Content-Language":    "en",
+		"Cache-Control":       "public, max-age=600",
+		"Content-Disposition": `attachment; filename="report.csv"`,
+	} {
+		attr := object.NewAttribute()
+		attr.SetKey(k)
+		attr.SetValue(v)
+		attrs = append(attrs, *attr)
+	}
+	obj.SetAttributes(attrs...)
+
+	newRequest := func(uri string) request {
+		var c fasthttp.RequestCtx
+		c.Request.SetRequestURI(uri)
+		return request{
+			RequestCtx:       &c,
+			log:              zap.NewNop(),
+			cacheControl:     "no-cache",
+			attributeHeaders: settings.AttributeHeaders(),
+		}
+	}
+
+	r := newRequest("/get/cid/oid")
+	r.setObjectHeaders(nil, obj)
+	r.setContentDisposition("")
+	require.Equal(t, "gzip", string(r.Response.Header.Peek(fasthttp.HeaderContentEncoding)))
+	require.Equal(t, "public, max-age=600", string(r.Response.Header.Peek(fasthttp.HeaderCacheControl)))
+	require.Equal(t, `attachment; filename="report.csv"`, string(r.Response.Header.Peek(fasthttp.HeaderContentDisposition)))
+	require.Empty(t, r.Response.Header.Peek(fasthttp.HeaderContentLanguage))
+	require.Equal(t, "en", string(r.Response.Header.Peek("X-Attribute-Content-Language")))
+
+	r = newRequest("/get/cid/oid?download=true")
+	r.setObjectHeaders(nil, obj)
+	r.setContentDisposition("")
+	require.Equal(t, "attachment", string(r.Response.Header.Peek(fasthttp.HeaderContentDisposition)))
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/nspcc-dev/neofs-http-gw/response"
 	"github.com/nspcc-dev/neofs-http-gw/tokens"
 	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
 	"github.com/nspcc-dev/neofs-sdk-go/client"
 	"github.com/nspcc-dev/neofs-sdk-go/object"
 	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
@@ -50,10 +51,49 @@ func (r request) headObject(clnt *pool.Pool, objectAddress oid.Address, signer u
 	}
 
 	r.Response.Header.Set(fasthttp.HeaderContentLength, strconv.FormatUint(obj.PayloadSize(), 10))
-	var contentType string
+	_, contentType := r.setObjectHeaders(clnt, obj)
+	if !r.checkPreconditions(obj) {
+		return
+	}
+
+	if len(contentType) == 0 {
+		contentType, err = r.detectContentType(clnt, objectAddress, obj.PayloadSize(), signer, btoken)
+		if err != nil {
+			r.handleNeoFSErr(err, start)
+			return
+		}
+	}
+	r.SetContentType(contentType)
+
+	if r.setCompressionHeaders(obj, contentType) != "" {
+		// the size of compressed payload is unknown, the same as for GET
+		r.Response.Header.SetContentLength(-1)
+	}
+}
+
+// setObjectHeaders sets response headers common for GET and HEAD requests
+// from the object header. Returns FileName and Content-Type attribute values
+// (if they are set).
+func (r request) setObjectHeaders(clnt *pool.Pool, obj *object.Object) (string, string) {
+	var filename, contentType string
+
+	r.Response.Header.Set(fasthttp.HeaderAcceptRanges, bytesUnit)
+	r.Response.Header.Set(fasthttp.HeaderETag, objectETag(obj))
 	for _, attr := range obj.Attributes() {
 		key := attr.Key()
 		val := attr.Value()
+		if key == object.AttributeFileName {
+			// it's encoded for Content-Disposition header, so can be arbitrary
+			filename = val
+		}
+		if header, ok := r.attributeHeaders[strings.ToLower(key)]; ok && isValidHeaderValue(val) {
+			if header == fasthttp.HeaderCacheControl {
+				// it's processed as the policy of the request
+				r.cacheControl = val
+			} else {
+				r.Response.Header.Set(header, val)
+			}
+		}
 		if !isValidToken(key) || !isValidValue(val) {
 			continue
 		}
@@ -78,26 +118,30 @@ func (r request) headObject(clnt *pool.Pool, objectAddress oid.Address, signer u
 	}
 
 	idsToResponse(&r.Response, obj)
+	r.setCacheHeaders(obj)
 
-	if len(contentType) == 0 {
-		contentType, _, err = readContentType(obj.PayloadSize(), func(sz uint64) (io.Reader, error) {
-			var prmRange client.PrmObjectRange
-			if btoken != nil {
-				prmRange.WithBearerToken(*btoken)
-			}
+	return filename, contentType
+}
 
-			resObj, err := clnt.ObjectRangeInit(r.appCtx, objectAddress.Container(), objectAddress.Object(), 0, sz, signer, prmRange)
-			if err != nil {
-				return nil, err
-			}
-			return resObj, nil
-		})
-		if err != nil && err != io.EOF {
-			r.handleNeoFSErr(err, start)
-			return
+// detectContentType reads the head of object payload to detect its Content-Type.
+func (r request) detectContentType(clnt *pool.Pool, objectAddress oid.Address, payloadSize uint64, signer user.Signer, btoken *bearer.Token) (string, error) {
+	contentType, _, err := readContentType(payloadSize, func(sz uint64) (io.Reader, error) {
+		var prmRange client.PrmObjectRange
+		if btoken != nil {
+			prmRange.WithBearerToken(*btoken)
 		}
+
+		resObj, err := clnt.ObjectRangeInit(r.appCtx, objectAddress.Container(), objectAddress.Object(), 0, sz, signer, prmRange)
+		if err != nil {
+			return nil, err
+		}
+		return resObj, nil
+	})
+	if err != nil && err != io.EOF {
+		return "", err
 	}
-	r.SetContentType(contentType)
+
+	return contentType, nil
 }
 
 func idsToResponse(resp *fasthttp.Response, obj *object.Object) {
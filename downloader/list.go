@@ -0,0 +1,344 @@
+package downloader
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultListLimit is the default and the maximum number of items in the
+	// object list page.
+	defaultListLimit = 1000
+
+	listKeySeparator = "\x00"
+
+	// listCacheSize limits the number of cached object list entries.
+	listCacheSize = 100000
+)
+
+// listCache caches object list entries, objects are immutable, so entries are
+// valid while the objects exist (removed ones are not found by search).
+type listCache struct {
+	mu      sync.Mutex
+	entries map[oid.Address]*listEntry
+}
+
+func (c *listCache) get(addr oid.Address) (*listEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[addr]
+	return entry, ok
+}
+
+// put caches the entry, some arbitrary entry is evicted if the cache is full.
+func (c *listCache) put(addr oid.Address, entry *listEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = make(map[oid.Address]*listEntry)
+	}
+	if len(c.entries) >= listCacheSize {
+		for k := range c.entries {
+			delete(c.entries, k)
+			break
+		}
+	}
+	c.entries[addr] = entry
+}
+
+// listEntry is the object of the object list.
+type listEntry struct {
+	ObjectID    string `json:"object_id"`
+	FilePath    string `json:"file_path"`
+	FileName    string `json:"file_name,omitempty"`
+	Size        uint64 `json:"size"`
+	ContentType string `json:"content_type,omitempty"`
+	Timestamp   *int64 `json:"timestamp,omitempty"`
+
+	version objectVersion
+}
+
+// listPage is the reply to the object list request.
+type listPage struct {
+	Objects    []listEntry `json:"objects"`
+	Prefixes   []string    `json:"prefixes"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// listItem is either the object or the common prefix of the object list,
+// items are sorted by keys.
+type listItem struct {
+	key    string
+	entry  *listEntry
+	prefix string
+}
+
+// listParams contains query parameters of the object list request.
+type listParams struct {
+	prefix    string
+	delimiter string
+	// cursor is the key of the last item of the previous page.
+	cursor string
+	limit  int
+	// newest makes only the newest object of every path listed.
+	newest bool
+}
+
+func parseListParams(args *fasthttp.Args) (listParams, error) {
+	p := listParams{
+		prefix:    string(args.Peek("prefix")),
+		delimiter: string(args.Peek("delimiter")),
+		limit:     defaultListLimit,
+	}
+
+	cursor, err := parseListCursor(args)
+	if err != nil {
+		return p, err
+	}
+	p.cursor = cursor
+
+	if limit := args.Peek("limit"); len(limit) != 0 {
+		val, err := strconv.Atoi(string(limit))
+		if err != nil || val <= 0 || val > defaultListLimit {
+			return p, fmt.Errorf("invalid limit '%s', it must be in range [1, %d]", limit, defaultListLimit)
+		}
+		p.limit = val
+	}
+
+	return p, nil
+}
+
+// parseListCursor returns the object list cursor passed with cursor query
+// argument, it's the key of the last item of the previous page.
+func parseListCursor(args *fasthttp.Args) (string, error) {
+	key, err := base64.RawURLEncoding.DecodeString(string(args.Peek("cursor")))
+	if err != nil {
+		return "", fmt.Errorf("invalid cursor: %w", err)
+	}
+	return string(key), nil
+}
+
+// newListEntry creates the object list entry from the object header.
+func newListEntry(id oid.ID, hdr *object.Object) *listEntry {
+	entry := &listEntry{
+		ObjectID: id.EncodeToString(),
+		Size:     hdr.PayloadSize(),
+		version:  objectVersion{id: id, hdr: hdr},
+	}
+	for _, attr := range hdr.Attributes() {
+		switch attr.Key() {
+		case object.AttributeFilePath:
+			entry.FilePath = attr.Value()
+		case object.AttributeFileName:
+			entry.FileName = attr.Value()
+		case object.AttributeContentType:
+			entry.ContentType = attr.Value()
+		}
+	}
+	if ts, ok := objectTimestamp(hdr); ok {
+		unix := ts.Unix()
+		entry.Timestamp = &unix
+	}
+	return entry
+}
+
+// listPageOf groups the entries (all having the prefix) by the delimiter and
+// returns the page of items following the cursor in path order.
+func listPageOf(entries []*listEntry, p listParams) listPage {
+	if p.newest {
+		entries = newestListEntries(entries)
+	}
+
+	var (
+		items    = make([]listItem, 0, len(entries))
+		prefixes = make(map[string]struct{})
+	)
+	for _, entry := range entries {
+		if p.delimiter != "" {
+			rest := strings.TrimPrefix(entry.FilePath, p.prefix)
+			if i := strings.Index(rest, p.delimiter); i >= 0 {
+				prefix := p.prefix + rest[:i+len(p.delimiter)]
+				if _, ok := prefixes[prefix]; !ok {
+					prefixes[prefix] = struct{}{}
+					items = append(items, listItem{key: prefix, prefix: prefix})
+				}
+				continue
+			}
+		}
+		// object ID makes keys of objects with the same path unique
+		items = append(items, listItem{key: entry.FilePath + listKeySeparator + entry.ObjectID, entry: entry})
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].key < items[j].key
+	})
+	if p.cursor != "" {
+		i := sort.Search(len(items), func(i int) bool {
+			return items[i].key > p.cursor
+		})
+		items = items[i:]
+	}
+
+	page := listPage{
+		Objects:  []listEntry{},
+		Prefixes: []string{},
+	}
+	if len(items) > p.limit {
+		items = items[:p.limit]
+		page.NextCursor = base64.RawURLEncoding.EncodeToString([]byte(items[len(items)-1].key))
+	}
+	for _, item := range items {
+		if item.entry != nil {
+			page.Objects = append(page.Objects, *item.entry)
+		} else {
+			page.Prefixes = append(page.Prefixes, item.prefix)
+		}
+	}
+
+	return page
+}
+
+// newestListEntries returns the newest entry of every path.
+func newestListEntries(entries []*listEntry) []*listEntry {
+	var (
+		res     = make([]*listEntry, 0, len(entries))
+		indices = make(map[string]int, len(entries))
+	)
+	for _, entry := range entries {
+		i, ok := indices[entry.FilePath]
+		if !ok {
+			indices[entry.FilePath] = len(res)
+			res = append(res, entry)
+			continue
+		}
+		if entry.version.newer(res[i].version) {
+			res[i] = entry
+		}
+	}
+	return res
+}
+
+// ListObjects handles requests for the list of objects with FilePath
+// attribute starting with the prefix.
+func (d *Downloader) ListObjects(c *fasthttp.RequestCtx) {
+	scid, _ := c.UserValue("cid").(string)
+	log := d.log.With(zap.String("cid", scid))
+
+	params, err := parseListParams(c.QueryArgs())
+	if err != nil {
+		log.Error("invalid list parameters", zap.Error(err))
+		response.Error(c, err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+	log = log.With(zap.String("prefix", params.prefix))
+
+	containerID, ok := d.archiveContainer(c, log, scid)
+	if !ok {
+		return
+	}
+
+	page, err := d.listObjects(c, log, *containerID, params)
+	if err != nil {
+		log.Error("could not list objects", zap.Error(err))
+		response.Error(c, "could not list objects: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	c.Response.Header.SetContentType(jsonContentType)
+	enc := json.NewEncoder(c)
+	enc.SetIndent("", "\t")
+	if err = enc.Encode(page); err != nil {
+		log.Error("could not encode object list", zap.Error(err))
+	}
+}
+
+// listObjects searches for objects with FilePath attribute starting with the
+// prefix and returns the page of them following the cursor in path order.
+func (d *Downloader) listObjects(c *fasthttp.RequestCtx, log *zap.Logger, cnrID cid.ID, p listParams) (listPage, error) {
+	filters := object.NewSearchFilters()
+	filters.AddFilter(object.AttributeFilePath, p.prefix, object.MatchCommonPrefix)
+
+	res, err := d.searchObjects(c, &cnrID, filters)
+	if err != nil {
+		return listPage{}, fmt.Errorf("could not search for objects: %w", err)
+	}
+	defer res.Close()
+
+	var ids []oid.ID
+	if err = res.Iterate(func(id oid.ID) bool {
+		ids = append(ids, id)
+		return false
+	}); err != nil {
+		return listPage{}, fmt.Errorf("read object list failed: %w", err)
+	}
+
+	return listPageOf(d.headListEntries(c, log, cnrID, ids), p), nil
+}
+
+// headListEntries returns list entries of the objects, objects missing in the
+// cache are headed concurrently. Objects failed to be headed (e.g. removed
+// after the search) are skipped.
+func (d *Downloader) headListEntries(c *fasthttp.RequestCtx, log *zap.Logger, cnrID cid.ID, ids []oid.ID) []*listEntry {
+	var prm client.PrmObjectHead
+	if btoken := bearerToken(c); btoken != nil {
+		prm.WithBearerToken(*btoken)
+	}
+
+	var (
+		wg      sync.WaitGroup
+		entries = make([]*listEntry, len(ids))
+		slots   = make(chan struct{}, d.settings.ZipConcurrency())
+	)
+
+	for i := range ids {
+		var addr oid.Address
+		addr.SetContainer(cnrID)
+		addr.SetObject(ids[i])
+		if entry, ok := d.listCache.get(addr); ok {
+			entries[i] = entry
+			continue
+		}
+
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+
+			hdr, err := d.pool.ObjectHead(d.appCtx, cnrID, ids[i], d.signer, prm)
+			if err != nil {
+				log.Warn("could not head listed object", zap.Stringer("oid", ids[i]), zap.Error(err))
+				return
+			}
+			entries[i] = newListEntry(ids[i], hdr)
+			d.listCache.put(addr, entries[i])
+		}(i)
+	}
+	wg.Wait()
+
+	res := entries[:0]
+	for _, entry := range entries {
+		if entry != nil {
+			res = append(res, entry)
+		}
+	}
+
+	return res
+}
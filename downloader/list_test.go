@@ -0,0 +1,127 @@
+package downloader
+
+import (
+	"testing"
+
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestParseListParams(t *testing.T) {
+	var args fasthttp.Args
+	p, err := parseListParams(&args)
+	require.NoError(t, err)
+	require.Equal(t, listParams{limit: defaultListLimit}, p)
+
+	args.Parse("prefix=dir/&delimiter=/&limit=10&cursor=ZGlyL2E")
+	p, err = parseListParams(&args)
+	require.NoError(t, err)
+	require.Equal(t, listParams{prefix: "dir/", delimiter: "/", cursor: "dir/a", limit: 10}, p)
+
+	for _, query := range []string{"limit=0", "limit=-1", "limit=1001", "limit=x", "cursor=!"} {
+		args.Parse(query)
+		_, err = parseListParams(&args)
+		require.Error(t, err, query)
+	}
+}
+
+func TestListPageOf(t *testing.T) {
+	entries := []*listEntry{
+		{ObjectID: "3", FilePath: "dir/sub/b.txt"},
+		{ObjectID: "1", FilePath: "dir/a.txt"},
+		{ObjectID: "2", FilePath: "dir/a.txt"},
+		{ObjectID: "4", FilePath: "dir/sub/c.txt"},
+		{ObjectID: "5", FilePath: "dir/z.txt"},
+		{ObjectID: "6", FilePath: "dir/other/d.txt"},
+	}
+	objectIDs := func(page listPage) []string {
+		var res []string
+		for _, e := range page.Objects {
+			res = append(res, e.ObjectID)
+		}
+		return res
+	}
+
+	page := listPageOf(entries, listParams{prefix: "dir/", limit: defaultListLimit})
+	require.Equal(t, []string{"1", "2", "6", "3", "4", "5"}, objectIDs(page))
+	require.Empty(t, page.Prefixes)
+	require.Empty(t, page.NextCursor)
+
+	page = listPageOf(entries, listParams{prefix: "dir/", delimiter: "/", limit: defaultListLimit})
+	require.Equal(t, []string{"1", "2", "5"}, objectIDs(page))
+	require.Equal(t, []string{"dir/other/", "dir/sub/"}, page.Prefixes)
+
+	// walk through the pages of two items
+	var (
+		cursor   string
+		ids      []string
+		prefixes []string
+	)
+	for i := 0; ; i++ {
+		require.Less(t, i, 5)
+
+		args := fasthttp.Args{}
+		args.Set("prefix", "dir/")
+		args.Set("delimiter", "/")
+		args.Set("limit", "2")
+		if cursor != "" {
+			args.Set("cursor", cursor)
+		}
+		p, err := parseListParams(&args)
+		require.NoError(t, err)
+
+		page = listPageOf(entries, p)
+		require.LessOrEqual(t, len(page.Objects)+len(page.Prefixes), 2)
+		ids = append(ids, objectIDs(page)...)
+		prefixes = append(prefixes, page.Prefixes...)
+		if cursor = page.NextCursor; cursor == "" {
+			break
+		}
+	}
+	require.Equal(t, []string{"1", "2", "5"}, ids)
+	require.Equal(t, []string{"dir/other/", "dir/sub/"}, prefixes)
+
+	// the cursor is the path, so pages don't depend on the search result
+	// order and the objects added before the cursor
+	p := listParams{prefix: "dir/", delimiter: "/", limit: 2}
+	page = listPageOf(entries, p)
+	require.Equal(t, []string{"1", "2"}, objectIDs(page))
+	p.cursor = "dir/a.txt" + listKeySeparator + "2"
+	entries = append([]*listEntry{{ObjectID: "0", FilePath: "dir/0.txt"}}, entries...)
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	page = listPageOf(entries, p)
+	require.Equal(t, []string{"dir/other/", "dir/sub/"}, page.Prefixes)
+	require.Empty(t, page.Objects)
+	require.NotEmpty(t, page.NextCursor)
+}
+
+func TestListCache(t *testing.T) {
+	var (
+		cache listCache
+		addr  oid.Address
+		entry = &listEntry{FilePath: "dir/a.txt"}
+	)
+	addr.SetContainer(cidtest.ID())
+	addr.SetObject(oidtest.ID())
+
+	_, ok := cache.get(addr)
+	require.False(t, ok)
+
+	cache.put(addr, entry)
+	res, ok := cache.get(addr)
+	require.True(t, ok)
+	require.Equal(t, entry, res)
+
+	for i := 0; i < listCacheSize; i++ {
+		var other oid.Address
+		other.SetContainer(cidtest.ID())
+		other.SetObject(oidtest.ID())
+		cache.put(other, entry)
+	}
+	require.Len(t, cache.entries, listCacheSize)
+}
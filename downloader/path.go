@@ -0,0 +1,123 @@
+package downloader
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/tokens"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// DownloadByPath handles GET requests of objects by FilePath (or FileName)
+// attribute.
+func (d *Downloader) DownloadByPath(c *fasthttp.RequestCtx) {
+	d.byPath(c, request.receiveFile)
+}
+
+// HeadByPath handles HEAD requests of objects by FilePath (or FileName)
+// attribute.
+func (d *Downloader) HeadByPath(c *fasthttp.RequestCtx) {
+	d.byPath(c, request.headObject)
+}
+
+// byPath is a wrapper similar to byAttribute for /{cid}/{path} route.
+func (d *Downloader) byPath(c *fasthttp.RequestCtx, f func(request, *pool.Pool, oid.Address, user.Signer)) {
+	scid, _ := c.UserValue("cid").(string)
+	log := d.log.With(zap.String("cid", scid))
+
+	objPath, dir, err := objectPath(c)
+	if err != nil {
+		log.Error("invalid object path", zap.Error(err))
+		response.Error(c, err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+	log = log.With(zap.String("path", objPath))
+
+	mode, err := ambiguityFromQuery(c.QueryArgs(), ambiguityNewest)
+	if err != nil {
+		log.Error("invalid ambiguity mode", zap.Error(err))
+		response.Error(c, err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	cnrID, err := utils.GetContainerID(d.appCtx, scid, d.containerResolver)
+	if err != nil {
+		log.Error("wrong container id", zap.Error(err))
+		response.Error(c, "wrong container id", fasthttp.StatusBadRequest)
+		return
+	}
+
+	if err = tokens.StoreBearerToken(c); err != nil {
+		log.Error("could not fetch and store bearer token", zap.Error(err))
+		response.Error(c, "could not fetch and store bearer token: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	if dir {
+		d.serveDirectory(c, log, scid, *cnrID, objPath, mode, f)
+		return
+	}
+
+	ids, err := d.findVersionsByPath(c, cnrID, objPath)
+	if err != nil {
+		log.Error("could not find object", zap.Error(err))
+		if errors.Is(err, errObjectNotFound) {
+			response.Error(c, "object not found", fasthttp.StatusNotFound)
+			return
+		}
+		response.Error(c, err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	d.serveVersion(c, log, *cnrID, ids, mode, f)
+}
+
+// routePath returns the unescaped path parameter of the request route. Path
+// unescaping is used, so '+' is kept as is.
+func routePath(c *fasthttp.RequestCtx) (string, error) {
+	val, _ := c.UserValue("path").(string)
+	res, err := url.PathUnescape(val)
+	if err != nil {
+		return "", fmt.Errorf("invalid path: %w", err)
+	}
+	return res, nil
+}
+
+// objectPath returns the unescaped path of /{cid}/{path} route request and
+// whether it's a directory path (empty or ending with slash).
+func objectPath(c *fasthttp.RequestCtx) (string, bool, error) {
+	objPath, err := routePath(c)
+	if err != nil {
+		return "", false, err
+	}
+	return objPath, objPath == "" || strings.HasSuffix(objPath, "/"), nil
+}
+
+// findVersionsByPath returns IDs of objects with FilePath attribute equal to
+// the path, objects are looked up by FileName attribute if there are none.
+func (d *Downloader) findVersionsByPath(c *fasthttp.RequestCtx, cnrID *cid.ID, objPath string) ([]oid.ID, error) {
+	return lookupPath(objPath, func(key, val string) ([]oid.ID, error) {
+		return d.findVersions(c, cnrID, key, val)
+	})
+}
+
+// lookupPath finds objects by FilePath attribute falling back to FileName one
+// with the given function returning errObjectNotFound if there are no matching
+// objects.
+func lookupPath(objPath string, find func(key, val string) ([]oid.ID, error)) ([]oid.ID, error) {
+	ids, err := find(object.AttributeFilePath, objPath)
+	if errors.Is(err, errObjectNotFound) {
+		return find(object.AttributeFileName, objPath)
+	}
+	return ids, err
+}
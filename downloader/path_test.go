@@ -0,0 +1,141 @@
+package downloader
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fasthttp/router"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestObjectPath(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		uri  string
+		path string
+		dir  bool
+		err  bool
+	}{
+		{name: "root", uri: "/cid/", path: "", dir: true},
+		{name: "file", uri: "/cid/index.html", path: "index.html"},
+		{name: "nested", uri: "/cid/docs/2023/report.pdf", path: "docs/2023/report.pdf"},
+		{name: "directory", uri: "/cid/docs/2023/", path: "docs/2023/", dir: true},
+		{name: "escaped segment", uri: "/cid/my%20docs/cat%2Bdog.jpeg", path: "my docs/cat+dog.jpeg"},
+		{name: "escaped slash", uri: "/cid/docs%2Freport.pdf", path: "docs/report.pdf"},
+		{name: "non-ascii", uri: "/cid/%D0%BA%D0%BE%D1%82/", path: "кот/", dir: true},
+		{name: "plus", uri: "/cid/dir/a+b.txt", path: "dir/a+b.txt"},
+		{name: "bad escape", uri: "/cid/dir/%zz.txt", err: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				c       fasthttp.RequestCtx
+				r       = router.New()
+				objPath string
+				dir     bool
+				err     error
+			)
+			r.GET("/{cid}/{path:*}", func(c *fasthttp.RequestCtx) {
+				objPath, dir, err = objectPath(c)
+			})
+
+			c.Request.SetRequestURI(tc.uri)
+			c.Request.Header.SetMethod(fasthttp.MethodGet)
+			r.Handler(&c)
+
+			require.Equal(t, fasthttp.StatusOK, c.Response.StatusCode())
+			if tc.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.path, objPath)
+			require.Equal(t, tc.dir, dir)
+		})
+	}
+}
+
+func TestLookupPath(t *testing.T) {
+	var (
+		v1, v2, v3 = oidtest.ID(), oidtest.ID(), oidtest.ID()
+		errSearch  = errors.New("search failed")
+	)
+
+	for _, tc := range []struct {
+		name     string
+		path     string
+		objects  map[string]map[string][]oid.ID // attribute -> value -> IDs
+		err      error
+		expected []oid.ID
+		searches []string
+	}{
+		{
+			name:     "nested path",
+			path:     "docs/2023/report.pdf",
+			objects:  map[string]map[string][]oid.ID{object.AttributeFilePath: {"docs/2023/report.pdf": {v1}}},
+			expected: []oid.ID{v1},
+			searches: []string{object.AttributeFilePath},
+		},
+		{
+			name: "multiple versions",
+			path: "index.html",
+			objects: map[string]map[string][]oid.ID{
+				object.AttributeFilePath: {"index.html": {v1, v2, v3}},
+				object.AttributeFileName: {"index.html": {v3}},
+			},
+			expected: []oid.ID{v1, v2, v3},
+			searches: []string{object.AttributeFilePath},
+		},
+		{
+			name: "file name fallback",
+			path: "cat.jpeg",
+			objects: map[string]map[string][]oid.ID{
+				object.AttributeFilePath: {"pics/cat.jpeg": {v1}},
+				object.AttributeFileName: {"cat.jpeg": {v2, v3}},
+			},
+			expected: []oid.ID{v2, v3},
+			searches: []string{object.AttributeFilePath, object.AttributeFileName},
+		},
+		{
+			name:     "escaped segment",
+			path:     "my docs/cat+dog.jpeg",
+			objects:  map[string]map[string][]oid.ID{object.AttributeFilePath: {"my docs/cat+dog.jpeg": {v1}}},
+			expected: []oid.ID{v1},
+			searches: []string{object.AttributeFilePath},
+		},
+		{
+			name:     "not found",
+			path:     "missing.txt",
+			objects:  map[string]map[string][]oid.ID{object.AttributeFilePath: {"missing.txt/": {v1}}},
+			err:      errObjectNotFound,
+			searches: []string{object.AttributeFilePath, object.AttributeFileName},
+		},
+		{
+			name:     "search error",
+			path:     "index.html",
+			err:      errSearch,
+			searches: []string{object.AttributeFilePath},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var searches []string
+			ids, err := lookupPath(tc.path, func(key, val string) ([]oid.ID, error) {
+				searches = append(searches, key)
+				require.Equal(t, tc.path, val)
+				if tc.err != nil && tc.err != errObjectNotFound {
+					return nil, tc.err
+				}
+				if ids := tc.objects[key][val]; len(ids) != 0 {
+					return ids, nil
+				}
+				return nil, errObjectNotFound
+			})
+			require.ErrorIs(t, err, tc.err)
+			require.Equal(t, tc.expected, ids)
+			require.Equal(t, tc.searches, searches)
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+)
+
+// memoryBudget limits the total size of payloads buffered by prefetcher.
+type memoryBudget struct {
+	mu   sync.Mutex
+	left uint64
+}
+
+// reserve tries to take size bytes from the budget, it never blocks to
+// avoid waiting for the memory held by objects queued after the current one.
+func (b *memoryBudget) reserve(size uint64) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if size > b.left {
+		return false
+	}
+	b.left -= size
+	return true
+}
+
+func (b *memoryBudget) release(size uint64) {
+	b.mu.Lock()
+	b.left += size
+	b.mu.Unlock()
+}
+
+// bufferedPayload is the object payload read to memory.
+type bufferedPayload struct {
+	*bytes.Reader
+	data []byte
+}
+
+func (bufferedPayload) Close() error {
+	return nil
+}
+
+// prefetchedObject is an object fetched for archive. Its payload is either
+// buffered (if it fits into memory budget) or an opened object stream.
+type prefetchedObject struct {
+	id       oid.ID
+	hdr      object.Object
+	payload  io.ReadCloser
+	reserved uint64
+	err      error
+}
+
+// objectPrefetcher fetches objects from search results concurrently, but
+// returns them in the order they were found.
+type objectPrefetcher struct {
+	d      *Downloader
+	cnrID  cid.ID
+	btoken *bearer.Token
+	budget *memoryBudget
+
+	// get fetches the object, it's fetch by default.
+	get func(ctx context.Context, id oid.ID) prefetchedObject
+	// slots limits the number of objects being fetched or waiting to be
+	// written to the archive.
+	slots chan struct{}
+	queue chan chan prefetchedObject
+	// iterErr is the search iteration error, it's safe to read it after the
+	// queue is closed.
+	iterErr error
+}
+
+func (d *Downloader) newObjectPrefetcher(cnrID cid.ID, btoken *bearer.Token) *objectPrefetcher {
+	concurrency := d.settings.ZipConcurrency()
+
+	p := &objectPrefetcher{
+		d:      d,
+		cnrID:  cnrID,
+		btoken: btoken,
+		budget: &memoryBudget{left: d.settings.ZipMemoryBudget()},
+		slots:  make(chan struct{}, concurrency),
+		queue:  make(chan chan prefetchedObject, concurrency),
+	}
+	p.get = p.fetch
+	return p
+}
+
+// start begins fetching objects passed by iterate function in background,
+// results are sent to the queue.
+func (p *objectPrefetcher) start(ctx context.Context, iterate func(func(oid.ID) bool) error) {
+	go func() {
+		defer close(p.queue)
+
+		p.iterErr = iterate(func(id oid.ID) bool {
+			select {
+			case p.slots <- struct{}{}:
+			case <-ctx.Done():
+				return true
+			}
+			if ctx.Err() != nil {
+				// the slot may be freed by drain after cancellation
+				<-p.slots
+				return true
+			}
+
+			ch := make(chan prefetchedObject, 1)
+			go func() {
+				ch <- p.get(ctx, id)
+			}()
+
+			select {
+			case p.queue <- ch:
+				return false
+			case <-ctx.Done():
+				p.done(<-ch)
+				return true
+			}
+		})
+	}()
+}
+
+func (p *objectPrefetcher) fetch(ctx context.Context, id oid.ID) prefetchedObject {
+	var prm client.PrmObjectGet
+	if p.btoken != nil {
+		prm.WithBearerToken(*p.btoken)
+	}
+
+	res := prefetchedObject{id: id}
+
+	hdr, payloadReader, err := p.d.pool.ObjectGetInit(ctx, p.cnrID, id, p.d.signer, prm)
+	if err != nil {
+		res.err = fmt.Errorf("get NeoFS object: %v", err)
+		return res
+	}
+	res.hdr = hdr
+	res.hdr.SetID(id)
+
+	size := hdr.PayloadSize()
+	if !p.budget.reserve(size) {
+		// too big to be buffered, it will be streamed when its turn comes
+		res.payload = payloadReader
+		return res
+	}
+
+	data := make([]byte, size)
+	_, err = io.ReadFull(payloadReader, data)
+	if closeErr := payloadReader.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		p.budget.release(size)
+		res.err = fmt.Errorf("read object payload: %w", err)
+		return res
+	}
+
+	res.payload = bufferedPayload{Reader: bytes.NewReader(data), data: data}
+	res.reserved = size
+	return res
+}
+
+// done releases resources held by the fetched object.
+func (p *objectPrefetcher) done(obj prefetchedObject) {
+	if obj.payload != nil {
+		_ = obj.payload.Close()
+	}
+	p.budget.release(obj.reserved)
+	<-p.slots
+}
+
+// drain releases all the objects left in the queue, the context passed to
+// start must be canceled before.
+func (p *objectPrefetcher) drain() {
+	for ch := range p.queue {
+		p.done(<-ch)
+	}
+}
@@ -0,0 +1,169 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryBudget(t *testing.T) {
+	b := memoryBudget{left: 10}
+
+	require.True(t, b.reserve(4))
+	require.True(t, b.reserve(6))
+	require.False(t, b.reserve(1))
+	require.True(t, b.reserve(0))
+
+	b.release(4)
+	require.False(t, b.reserve(5))
+	require.True(t, b.reserve(4))
+}
+
+func newTestPrefetcher(concurrency int, get func(context.Context, oid.ID) prefetchedObject) *objectPrefetcher {
+	return &objectPrefetcher{
+		budget: &memoryBudget{},
+		get:    get,
+		slots:  make(chan struct{}, concurrency),
+		queue:  make(chan chan prefetchedObject, concurrency),
+	}
+}
+
+// iterateIDs returns iteration function passing the IDs and returning the
+// error after them.
+func iterateIDs(ids []oid.ID, err error) func(func(oid.ID) bool) error {
+	return func(f func(oid.ID) bool) error {
+		for _, id := range ids {
+			if f(id) {
+				return nil
+			}
+		}
+		return err
+	}
+}
+
+func newTestIDs(count int) []oid.ID {
+	ids := make([]oid.ID, count)
+	for i := range ids {
+		ids[i] = oidtest.ID()
+	}
+	return ids
+}
+
+func TestPrefetcherOrder(t *testing.T) {
+	const count = 5
+
+	var (
+		ids       = newTestIDs(count)
+		indexes   = make(map[oid.ID]int, count)
+		fetched   = make([]chan struct{}, count)
+		mu        sync.Mutex
+		completed []oid.ID
+	)
+	for i := range ids {
+		indexes[ids[i]] = i
+		fetched[i] = make(chan struct{})
+	}
+
+	// objects are fetched in the reverse order
+	p := newTestPrefetcher(count, func(_ context.Context, id oid.ID) prefetchedObject {
+		i := indexes[id]
+		if i+1 < count {
+			<-fetched[i+1]
+		}
+		mu.Lock()
+		completed = append(completed, id)
+		mu.Unlock()
+		close(fetched[i])
+		return prefetchedObject{id: id}
+	})
+	p.start(context.Background(), iterateIDs(ids, nil))
+
+	var res []oid.ID
+	for ch := range p.queue {
+		obj := <-ch
+		require.NoError(t, obj.err)
+		res = append(res, obj.id)
+		p.done(obj)
+	}
+
+	require.NoError(t, p.iterErr)
+	require.Equal(t, ids, res)
+	for i := range completed {
+		require.Equal(t, ids[count-1-i], completed[i])
+	}
+}
+
+func TestPrefetcherErrors(t *testing.T) {
+	var (
+		ids      = newTestIDs(4)
+		errFetch = errors.New("fetch error")
+		errIter  = errors.New("iteration error")
+	)
+
+	p := newTestPrefetcher(2, func(_ context.Context, id oid.ID) prefetchedObject {
+		if id == ids[1] {
+			return prefetchedObject{id: id, err: errFetch}
+		}
+		return prefetchedObject{id: id, payload: io.NopCloser(strings.NewReader("payload"))}
+	})
+	p.start(context.Background(), iterateIDs(ids, errIter))
+
+	var res []oid.ID
+	for ch := range p.queue {
+		obj := <-ch
+		if obj.id == ids[1] {
+			require.ErrorIs(t, obj.err, errFetch)
+		} else {
+			require.NoError(t, obj.err)
+		}
+		res = append(res, obj.id)
+		p.done(obj)
+	}
+
+	// objects following the failed one are returned too
+	require.Equal(t, ids, res)
+	require.ErrorIs(t, p.iterErr, errIter)
+	require.Len(t, p.slots, 0)
+}
+
+func TestPrefetcherCancel(t *testing.T) {
+	const concurrency = 2
+
+	var (
+		ctx, cancel = context.WithCancel(context.Background())
+		ids         = newTestIDs(100)
+		fetched     atomic.Int32
+	)
+	defer cancel()
+
+	p := newTestPrefetcher(concurrency, func(ctx context.Context, id oid.ID) prefetchedObject {
+		fetched.Add(1)
+		if id == ids[0] {
+			return prefetchedObject{id: id}
+		}
+		<-ctx.Done()
+		return prefetchedObject{id: id, err: ctx.Err()}
+	})
+	p.start(ctx, iterateIDs(ids, nil))
+
+	obj := <-<-p.queue
+	require.NoError(t, obj.err)
+	require.Equal(t, ids[0], obj.id)
+	p.done(obj)
+
+	cancel()
+	p.drain()
+
+	require.NoError(t, p.iterErr)
+	require.Len(t, p.slots, 0)
+	// the search is stopped once all the slots are taken
+	require.LessOrEqual(t, int(fetched.Load()), concurrency+1)
+}
@@ -0,0 +1,295 @@
+package downloader
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+const (
+	bytesUnit = "bytes"
+
+	// maxRanges is the maximum number of ranges (after coalescing) served in
+	// multipart/byteranges reply, Range header is ignored if there are more.
+	maxRanges = 16
+)
+
+var (
+	// errInvalidRange is returned for syntactically incorrect Range header,
+	// such header must be ignored.
+	errInvalidRange = errors.New("invalid range")
+	// errRangeNotSatisfiable is returned when none of the requested ranges
+	// overlaps the payload.
+	errRangeNotSatisfiable = errors.New("range not satisfiable")
+)
+
+// httpRange is a byte range of object payload.
+type httpRange struct {
+	start  uint64
+	length uint64
+}
+
+func (r httpRange) contentRange(size uint64) string {
+	return fmt.Sprintf("%s %d-%d/%d", bytesUnit, r.start, r.start+r.length-1, size)
+}
+
+func (r httpRange) mimeHeader(contentType string, size uint64) textproto.MIMEHeader {
+	return textproto.MIMEHeader{
+		fasthttp.HeaderContentRange: {r.contentRange(size)},
+		fasthttp.HeaderContentType:  {contentType},
+	}
+}
+
+// coalesceRanges sorts ranges and merges overlapping and adjacent ones.
+func coalesceRanges(ranges []httpRange) []httpRange {
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].start < ranges[j].start
+	})
+
+	res := ranges[:1]
+	for _, ra := range ranges[1:] {
+		last := &res[len(res)-1]
+		if ra.start <= last.start+last.length {
+			if end := ra.start + ra.length; end > last.start+last.length {
+				last.length = end - last.start
+			}
+			continue
+		}
+		res = append(res, ra)
+	}
+
+	return res
+}
+
+// countingWriter counts bytes written to it.
+type countingWriter uint64
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	*w += countingWriter(len(p))
+	return len(p), nil
+}
+
+// multipartSize returns the size of multipart/byteranges body for the given
+// ranges and the boundary it has to be written with.
+func multipartSize(ranges []httpRange, contentType string, size uint64) (uint64, string) {
+	var (
+		w     countingWriter
+		total uint64
+		mw    = multipart.NewWriter(&w)
+	)
+	for _, ra := range ranges {
+		// CreatePart writes only part headers to the counter, payload is added separately
+		_, _ = mw.CreatePart(ra.mimeHeader(contentType, size))
+		total += ra.length
+	}
+	_ = mw.Close()
+
+	return total + uint64(w), mw.Boundary()
+}
+
+// parseRange parses Range header value (RFC 7233) for the payload of the
+// given size. Unsatisfiable ranges are skipped, if there are no satisfiable
+// ones left errRangeNotSatisfiable is returned.
+func parseRange(s string, size uint64) ([]httpRange, error) {
+	if !strings.HasPrefix(s, bytesUnit+"=") {
+		return nil, errInvalidRange
+	}
+	spec := strings.TrimPrefix(s, bytesUnit+"=")
+
+	var (
+		ranges []httpRange
+		parsed int
+	)
+	for _, ra := range strings.Split(spec, ",") {
+		ra = strings.TrimSpace(ra)
+		if ra == "" {
+			continue
+		}
+		first, last, ok := strings.Cut(ra, "-")
+		if !ok {
+			return nil, errInvalidRange
+		}
+		first, last = strings.TrimSpace(first), strings.TrimSpace(last)
+		parsed++
+
+		if first == "" {
+			// suffix range: the last N bytes
+			n, err := strconv.ParseUint(last, 10, 64)
+			if err != nil {
+				return nil, errInvalidRange
+			}
+			if n == 0 || size == 0 {
+				continue
+			}
+			if n > size {
+				n = size
+			}
+			ranges = append(ranges, httpRange{start: size - n, length: n})
+			continue
+		}
+
+		start, err := strconv.ParseUint(first, 10, 64)
+		if err != nil {
+			return nil, errInvalidRange
+		}
+		end := size - 1
+		if last != "" {
+			if end, err = strconv.ParseUint(last, 10, 64); err != nil || end < start {
+				return nil, errInvalidRange
+			}
+		}
+		if start >= size {
+			continue
+		}
+		if end >= size {
+			end = size - 1
+		}
+		ranges = append(ranges, httpRange{start: start, length: end - start + 1})
+	}
+
+	if parsed == 0 {
+		return nil, errInvalidRange
+	}
+	if len(ranges) == 0 {
+		return nil, errRangeNotSatisfiable
+	}
+
+	return ranges, nil
+}
+
+// receiveRange serves the payload range requested via Range header. It returns
+// false if the header is to be ignored and the whole payload has to be sent.
+func (r request) receiveRange(clnt *pool.Pool, objectAddress oid.Address, signer user.Signer, btoken *bearer.Token, rangeHeader string, start time.Time) bool {
+	var prm client.PrmObjectHead
+	if btoken != nil {
+		prm.WithBearerToken(*btoken)
+	}
+
+	hdr, err := clnt.ObjectHead(r.appCtx, objectAddress.Container(), objectAddress.Object(), signer, prm)
+	if err != nil {
+		r.handleNeoFSErr(err, start)
+		return true
+	}
+
+	payloadSize := hdr.PayloadSize()
+
+	filename, contentType := r.setObjectHeaders(clnt, hdr)
+	if !r.checkPreconditions(hdr) {
+		return true
+	}
+	if !r.checkIfRange(hdr) {
+		r.log.Debug("If-Range precondition failed, ignore Range header", zap.String("range", rangeHeader))
+		return false
+	}
+
+	ranges, err := parseRange(rangeHeader, payloadSize)
+	if errors.Is(err, errRangeNotSatisfiable) {
+		r.log.Debug("requested range is not satisfiable", zap.String("range", rangeHeader))
+		response.Error(r.RequestCtx, "Range Not Satisfiable", fasthttp.StatusRequestedRangeNotSatisfiable)
+		r.Response.Header.Set(fasthttp.HeaderContentRange, bytesUnit+" */"+strconv.FormatUint(payloadSize, 10))
+		return true
+	}
+	if err != nil {
+		r.log.Debug("ignore Range header", zap.String("range", rangeHeader), zap.Error(err))
+		return false
+	}
+	if ranges = coalesceRanges(ranges); len(ranges) > maxRanges {
+		r.log.Debug("too many ranges requested, ignore Range header", zap.String("range", rangeHeader))
+		return false
+	}
+
+	if len(contentType) == 0 {
+		if contentType, err = r.detectContentType(clnt, objectAddress, payloadSize, signer, btoken); err != nil {
+			r.handleNeoFSErr(err, start)
+			return true
+		}
+	}
+
+	var prmRange client.PrmObjectRange
+	if btoken != nil {
+		prmRange.WithBearerToken(*btoken)
+	}
+
+	r.setContentDisposition(filename)
+	r.Response.SetStatusCode(fasthttp.StatusPartialContent)
+
+	if len(ranges) > 1 {
+		r.sendMultipartRanges(clnt, objectAddress, signer, prmRange, ranges, contentType, payloadSize)
+		return true
+	}
+
+	payload, err := clnt.ObjectRangeInit(r.appCtx, objectAddress.Container(), objectAddress.Object(), ranges[0].start, ranges[0].length, signer, prmRange)
+	if err != nil {
+		r.handleNeoFSErr(err, start)
+		return true
+	}
+
+	r.SetContentType(contentType)
+	r.Response.Header.Set(fasthttp.HeaderContentLength, strconv.FormatUint(ranges[0].length, 10))
+	r.Response.Header.Set(fasthttp.HeaderContentRange, ranges[0].contentRange(payloadSize))
+	r.Response.SetBodyStream(payload, int(ranges[0].length))
+
+	return true
+}
+
+// sendMultipartRanges streams multipart/byteranges body consisting of the
+// given payload ranges, each of them is fetched by a separate range request.
+func (r request) sendMultipartRanges(clnt *pool.Pool, objectAddress oid.Address, signer user.Signer, prm client.PrmObjectRange,
+	ranges []httpRange, contentType string, payloadSize uint64) {
+	bodySize, boundary := multipartSize(ranges, contentType, payloadSize)
+
+	pr, pw := io.Pipe()
+	go func() {
+		mw := multipart.NewWriter(pw)
+		if err := mw.SetBoundary(boundary); err != nil {
+			_ = pw.CloseWithError(err)
+			return
+		}
+
+		for _, ra := range ranges {
+			part, err := mw.CreatePart(ra.mimeHeader(contentType, payloadSize))
+			if err != nil {
+				_ = pw.CloseWithError(err)
+				return
+			}
+
+			payload, err := clnt.ObjectRangeInit(r.appCtx, objectAddress.Container(), objectAddress.Object(), ra.start, ra.length, signer, prm)
+			if err != nil {
+				r.log.Error("could not receive object range", zap.String("range", ra.contentRange(payloadSize)), zap.Error(err))
+				_ = pw.CloseWithError(err)
+				return
+			}
+
+			_, err = io.Copy(part, payload)
+			if closeErr := payload.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				r.log.Error("could not copy object range", zap.String("range", ra.contentRange(payloadSize)), zap.Error(err))
+				_ = pw.CloseWithError(err)
+				return
+			}
+		}
+
+		_ = pw.CloseWithError(mw.Close())
+	}()
+
+	r.SetContentType("multipart/byteranges; boundary=" + boundary)
+	r.Response.Header.Set(fasthttp.HeaderContentLength, strconv.FormatUint(bodySize, 10))
+	r.Response.SetBodyStream(pr, int(bodySize))
+}
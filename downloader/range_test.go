@@ -0,0 +1,140 @@
+package downloader
+
+import (
+	"bytes"
+	"mime/multipart"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRange(t *testing.T) {
+	const size = 1000
+
+	for _, tc := range []struct {
+		name     string
+		header   string
+		expected []httpRange
+		err      error
+	}{
+		{
+			name:     "closed range",
+			header:   "bytes=0-99",
+			expected: []httpRange{{start: 0, length: 100}},
+		},
+		{
+			name:     "open-ended range",
+			header:   "bytes=900-",
+			expected: []httpRange{{start: 900, length: 100}},
+		},
+		{
+			name:     "suffix range",
+			header:   "bytes=-10",
+			expected: []httpRange{{start: 990, length: 10}},
+		},
+		{
+			name:     "suffix range bigger than payload",
+			header:   "bytes=-5000",
+			expected: []httpRange{{start: 0, length: size}},
+		},
+		{
+			name:     "end is truncated",
+			header:   "bytes=990-5000",
+			expected: []httpRange{{start: 990, length: 10}},
+		},
+		{
+			name:     "several ranges",
+			header:   "bytes=0-0, -1",
+			expected: []httpRange{{start: 0, length: 1}, {start: 999, length: 1}},
+		},
+		{
+			name:     "unsatisfiable range is skipped",
+			header:   "bytes=5000-6000,0-1",
+			expected: []httpRange{{start: 0, length: 2}},
+		},
+		{
+			name:   "start beyond payload",
+			header: "bytes=1000-",
+			err:    errRangeNotSatisfiable,
+		},
+		{
+			name:   "zero suffix",
+			header: "bytes=-0",
+			err:    errRangeNotSatisfiable,
+		},
+		{
+			name:   "wrong unit",
+			header: "items=0-1",
+			err:    errInvalidRange,
+		},
+		{
+			name:   "end before start",
+			header: "bytes=10-1",
+			err:    errInvalidRange,
+		},
+		{
+			name:   "no dash",
+			header: "bytes=10",
+			err:    errInvalidRange,
+		},
+		{
+			name:   "empty set",
+			header: "bytes=",
+			err:    errInvalidRange,
+		},
+		{
+			name:   "not a number",
+			header: "bytes=a-b",
+			err:    errInvalidRange,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ranges, err := parseRange(tc.header, size)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, ranges)
+		})
+	}
+
+	t.Run("empty payload", func(t *testing.T) {
+		_, err := parseRange("bytes=0-", 0)
+		require.ErrorIs(t, err, errRangeNotSatisfiable)
+	})
+}
+
+func TestCoalesceRanges(t *testing.T) {
+	ranges := coalesceRanges([]httpRange{
+		{start: 500, length: 100},
+		{start: 0, length: 10},
+		{start: 5, length: 10},
+		{start: 15, length: 5},
+		{start: 550, length: 10},
+	})
+	require.Equal(t, []httpRange{{start: 0, length: 20}, {start: 500, length: 100}}, ranges)
+}
+
+func TestMultipartSize(t *testing.T) {
+	const (
+		contentType = "text/plain"
+		size        = 1000
+	)
+	ranges := []httpRange{{start: 0, length: 10}, {start: 100, length: 20}}
+
+	total, boundary := multipartSize(ranges, contentType, size)
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	require.NoError(t, mw.SetBoundary(boundary))
+	for _, ra := range ranges {
+		part, err := mw.CreatePart(ra.mimeHeader(contentType, size))
+		require.NoError(t, err)
+		_, err = part.Write(make([]byte, ra.length))
+		require.NoError(t, err)
+	}
+	require.NoError(t, mw.Close())
+
+	require.EqualValues(t, buf.Len(), total)
+}
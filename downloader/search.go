@@ -0,0 +1,272 @@
+package downloader
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-sdk-go/bearer"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+const (
+	// searchAttributeArg is the query argument containing the name of object
+	// attribute to be returned in search results, * means all attributes.
+	searchAttributeArg = "attr"
+
+	ndjsonContentType = "application/x-ndjson"
+)
+
+// numericSearchOps maps numeric search filter operations to the checks of
+// comparison results.
+var numericSearchOps = map[string]func(int) bool{
+	"gt": func(cmp int) bool { return cmp > 0 },
+	"ge": func(cmp int) bool { return cmp >= 0 },
+	"lt": func(cmp int) bool { return cmp < 0 },
+	"le": func(cmp int) bool { return cmp <= 0 },
+}
+
+// numericFilter is the search filter comparing attribute values as numbers.
+// NeoFS API has no numeric match types, so such filters are checked by the
+// gateway using object headers.
+type numericFilter struct {
+	key   string
+	val   *big.Rat
+	check func(int) bool
+}
+
+// match checks whether the object attribute is a number satisfying the filter.
+func (f numericFilter) match(obj *object.Object) bool {
+	for _, attr := range obj.Attributes() {
+		if attr.Key() != f.key {
+			continue
+		}
+		val, ok := new(big.Rat).SetString(attr.Value())
+		return ok && f.check(val.Cmp(f.val))
+	}
+	return false
+}
+
+// parseSearchQuery parses all the search filters passed in query arguments
+// including numeric ones.
+func parseSearchQuery(args *fasthttp.Args) (object.SearchFilters, []numericFilter, error) {
+	var (
+		filters object.SearchFilters
+		numeric []numericFilter
+	)
+	for _, arg := range args.PeekMulti(searchFilterArg) {
+		parts := strings.SplitN(string(arg), ":", 3)
+		if len(parts) == 3 && parts[0] != "" {
+			if check, ok := numericSearchOps[parts[1]]; ok {
+				val, ok := new(big.Rat).SetString(parts[2])
+				if !ok {
+					return nil, nil, fmt.Errorf("invalid number '%s' in filter '%s'", parts[2], arg)
+				}
+				numeric = append(numeric, numericFilter{key: parts[0], val: val, check: check})
+				continue
+			}
+		}
+
+		key, val, op, err := parseSearchFilter(string(arg))
+		if err != nil {
+			return nil, nil, err
+		}
+		filters.AddFilter(key, val, op)
+	}
+
+	return filters, numeric, nil
+}
+
+// searchResult is the element of search results.
+type searchResult struct {
+	ObjectID   string            `json:"object_id"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// searchStream describes search results to be streamed.
+type searchStream struct {
+	log     *zap.Logger
+	cnrID   cid.ID
+	btoken  *bearer.Token
+	res     *client.ObjectListReader
+	numeric []numericFilter
+	// attrs are the names of returned attributes, nil means all of them.
+	attrs  map[string]struct{}
+	ndjson bool
+	limit  uint64
+}
+
+// needHeader checks whether object headers are required to form the results.
+func (s searchStream) needHeader() bool {
+	return len(s.numeric) != 0 || s.attrs == nil || len(s.attrs) != 0
+}
+
+// result forms the search result from the object header.
+func (s searchStream) result(id oid.ID, hdr *object.Object) (searchResult, bool) {
+	res := searchResult{ObjectID: id.EncodeToString()}
+	if hdr == nil {
+		return res, true
+	}
+
+	for _, f := range s.numeric {
+		if !f.match(hdr) {
+			return res, false
+		}
+	}
+
+	for _, attr := range hdr.Attributes() {
+		if _, ok := s.attrs[attr.Key()]; s.attrs == nil || ok {
+			if res.Attributes == nil {
+				res.Attributes = make(map[string]string)
+			}
+			res.Attributes[attr.Key()] = attr.Value()
+		}
+	}
+
+	return res, true
+}
+
+// SearchObjects handles requests for IDs and attributes of objects matching
+// the filters.
+func (d *Downloader) SearchObjects(c *fasthttp.RequestCtx) {
+	scid, _ := c.UserValue("cid").(string)
+	log := d.log.With(zap.String("cid", scid))
+
+	filters, numeric, err := parseSearchQuery(c.QueryArgs())
+	if err != nil {
+		log.Error("invalid search filter", zap.Error(err))
+		response.Error(c, "invalid search filter: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	stream := searchStream{
+		log:     log,
+		numeric: numeric,
+		attrs:   make(map[string]struct{}),
+	}
+
+	switch format := string(c.QueryArgs().Peek("format")); format {
+	case "":
+		stream.ndjson = strings.Contains(string(c.Request.Header.Peek(fasthttp.HeaderAccept)), ndjsonContentType)
+	case "json":
+	case "ndjson":
+		stream.ndjson = true
+	default:
+		log.Error("unknown search result format", zap.String("format", format))
+		response.Error(c, "unknown format '"+format+"', expected json or ndjson", fasthttp.StatusBadRequest)
+		return
+	}
+
+	if c.QueryArgs().Has("limit") {
+		limit, err := c.QueryArgs().GetUint("limit")
+		if err != nil || limit == 0 {
+			log.Error("invalid search limit", zap.Error(err))
+			response.Error(c, "invalid limit, it must be a positive integer", fasthttp.StatusBadRequest)
+			return
+		}
+		stream.limit = uint64(limit)
+	}
+
+	for _, attr := range c.QueryArgs().PeekMulti(searchAttributeArg) {
+		if string(attr) == "*" {
+			stream.attrs = nil
+			break
+		}
+		stream.attrs[string(attr)] = struct{}{}
+	}
+
+	containerID, ok := d.archiveContainer(c, log, scid)
+	if !ok {
+		return
+	}
+	stream.cnrID = *containerID
+	stream.btoken = bearerToken(c)
+
+	stream.res, err = d.searchObjects(c, containerID, filters)
+	if err != nil {
+		log.Error("could not search for objects", zap.Error(err))
+		response.Error(c, "could not search for objects: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	contentType := jsonContentType
+	if stream.ndjson {
+		contentType = ndjsonContentType
+	}
+	c.Response.Header.SetContentType(contentType)
+	c.Response.SetStatusCode(fasthttp.StatusOK)
+	c.Response.SetBodyStream(d.searchBody(stream), -1)
+}
+
+// searchBody returns the reader of search results which are written in
+// background as the object list reader yields them. The stream is broken if
+// the search fails after results have been started to be sent.
+func (d *Downloader) searchBody(s searchStream) io.Reader {
+	pr, pw := io.Pipe()
+	go func() {
+		defer s.res.Close()
+
+		var prm client.PrmObjectHead
+		if s.btoken != nil {
+			prm.WithBearerToken(*s.btoken)
+		}
+
+		var (
+			count uint64
+			conn  = &errorTrackingWriter{w: pw}
+			enc   = json.NewEncoder(conn)
+		)
+		if !s.ndjson {
+			_, _ = io.WriteString(conn, "[\n")
+		}
+
+		iterErr := s.res.Iterate(func(id oid.ID) bool {
+			var hdr *object.Object
+			if s.needHeader() {
+				var err error
+				if hdr, err = d.pool.ObjectHead(d.appCtx, s.cnrID, id, d.signer, prm); err != nil {
+					// the object can be removed after the search
+					s.log.Warn("could not head found object", zap.Stringer("oid", id), zap.Error(err))
+					return false
+				}
+			}
+
+			res, ok := s.result(id, hdr)
+			if !ok {
+				return false
+			}
+			if !s.ndjson && count > 0 {
+				_, _ = io.WriteString(conn, ",")
+			}
+			_ = enc.Encode(res)
+			count++
+
+			return conn.err != nil || s.limit != 0 && count >= s.limit
+		})
+
+		var err error
+		switch {
+		case conn.err != nil:
+			s.log.Debug("search results stream is interrupted", zap.Error(conn.err))
+			err = conn.err
+		case iterErr != nil:
+			s.log.Error("could not read search results", zap.Error(iterErr))
+			err = iterErr
+		default:
+			if !s.ndjson {
+				_, _ = io.WriteString(conn, "]\n")
+			}
+		}
+		_ = pw.CloseWithError(err)
+	}()
+
+	return pr
+}
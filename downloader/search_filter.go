@@ -0,0 +1,78 @@
+package downloader
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	// searchFilterArg is the query argument containing search filter.
+	searchFilterArg = "filter"
+	// andFilterArg is the query argument containing additional attribute
+	// of the object looked up by attribute.
+	andFilterArg = "and"
+)
+
+// searchMatchTypes maps search filter operations to match types.
+var searchMatchTypes = map[string]object.SearchMatchType{
+	"eq":          object.MatchStringEqual,
+	"ne":          object.MatchStringNotEqual,
+	"prefix":      object.MatchCommonPrefix,
+	"not-present": object.MatchNotPresent,
+}
+
+// parseSearchFilter parses search filter in Key:op:value format, value is
+// optional for not-present operation.
+func parseSearchFilter(s string) (string, string, object.SearchMatchType, error) {
+	parts := strings.SplitN(s, ":", 3)
+	if len(parts) < 2 || parts[0] == "" {
+		return "", "", 0, fmt.Errorf("invalid filter '%s', expected Key:op:value", s)
+	}
+
+	op, ok := searchMatchTypes[parts[1]]
+	if !ok {
+		return "", "", 0, fmt.Errorf("unknown filter operation '%s'", parts[1])
+	}
+
+	var val string
+	if len(parts) == 3 {
+		val = parts[2]
+	} else if op != object.MatchNotPresent {
+		return "", "", 0, fmt.Errorf("invalid filter '%s', value is missing", s)
+	}
+
+	return parts[0], val, op, nil
+}
+
+// searchFiltersFromQuery parses all the search filters passed in query
+// arguments.
+func searchFiltersFromQuery(args *fasthttp.Args) (object.SearchFilters, error) {
+	var filters object.SearchFilters
+	for _, arg := range args.PeekMulti(searchFilterArg) {
+		key, val, op, err := parseSearchFilter(string(arg))
+		if err != nil {
+			return nil, err
+		}
+		filters.AddFilter(key, val, op)
+	}
+
+	return filters, nil
+}
+
+// andFiltersFromQuery parses additional attributes in Key:value format passed
+// in query arguments to equality filters.
+func andFiltersFromQuery(args *fasthttp.Args) (object.SearchFilters, error) {
+	var filters object.SearchFilters
+	for _, arg := range args.PeekMulti(andFilterArg) {
+		key, val, ok := strings.Cut(string(arg), ":")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid attribute '%s', expected Key:value", arg)
+		}
+		filters.AddFilter(key, val, object.MatchStringEqual)
+	}
+
+	return filters, nil
+}
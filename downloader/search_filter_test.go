@@ -0,0 +1,73 @@
+package downloader
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestParseSearchFilter(t *testing.T) {
+	for _, tc := range []struct {
+		filter string
+		key    string
+		val    string
+		op     object.SearchMatchType
+	}{
+		{filter: "Project:eq:alpha", key: "Project", val: "alpha", op: object.MatchStringEqual},
+		{filter: "Project:ne:alpha", key: "Project", val: "alpha", op: object.MatchStringNotEqual},
+		{filter: "FilePath:prefix:dir/", key: "FilePath", val: "dir/", op: object.MatchCommonPrefix},
+		{filter: "Time:eq:12:00", key: "Time", val: "12:00", op: object.MatchStringEqual},
+		{filter: "Project:eq:", key: "Project", val: "", op: object.MatchStringEqual},
+		{filter: "Type:not-present", key: "Type", op: object.MatchNotPresent},
+	} {
+		key, val, op, err := parseSearchFilter(tc.filter)
+		require.NoError(t, err, tc.filter)
+		require.Equal(t, tc.key, key)
+		require.Equal(t, tc.val, val)
+		require.Equal(t, tc.op, op)
+	}
+
+	for _, filter := range []string{"", "Project", ":eq:alpha", "Project:like:alpha", "Project:eq"} {
+		_, _, _, err := parseSearchFilter(filter)
+		require.Error(t, err, filter)
+	}
+}
+
+func TestSearchFiltersFromQuery(t *testing.T) {
+	var args fasthttp.Args
+	args.Parse("filter=Project:eq:alpha&filter=Type%3Aeq%3Areport&other=value")
+
+	filters, err := searchFiltersFromQuery(&args)
+	require.NoError(t, err)
+	require.Len(t, filters, 2)
+	require.Equal(t, "Project", filters[0].Header())
+	require.Equal(t, "alpha", filters[0].Value())
+	require.Equal(t, "Type", filters[1].Header())
+	require.Equal(t, "report", filters[1].Value())
+
+	args.Parse("filter=Project")
+	_, err = searchFiltersFromQuery(&args)
+	require.Error(t, err)
+}
+
+func TestAndFiltersFromQuery(t *testing.T) {
+	var args fasthttp.Args
+	args.Parse("and=Build:1234&and=Time%3A12%3A00&and=Empty:&other=value")
+
+	filters, err := andFiltersFromQuery(&args)
+	require.NoError(t, err)
+	require.Len(t, filters, 3)
+	for i, exp := range [][2]string{{"Build", "1234"}, {"Time", "12:00"}, {"Empty", ""}} {
+		require.Equal(t, exp[0], filters[i].Header())
+		require.Equal(t, exp[1], filters[i].Value())
+		require.Equal(t, object.MatchStringEqual, filters[i].Operation())
+	}
+
+	for _, query := range []string{"and=Build", "and=:1234"} {
+		args.Parse(query)
+		_, err = andFiltersFromQuery(&args)
+		require.Error(t, err, query)
+	}
+}
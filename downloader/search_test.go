@@ -0,0 +1,79 @@
+package downloader
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	var args fasthttp.Args
+	args.Parse("filter=Project:eq:alpha&filter=Size:ge:10&filter=Rate:lt:0.5&filter=Type:not-present")
+
+	filters, numeric, err := parseSearchQuery(&args)
+	require.NoError(t, err)
+	require.Len(t, filters, 2)
+	require.Equal(t, "Project", filters[0].Header())
+	require.Equal(t, "Type", filters[1].Header())
+	require.Len(t, numeric, 2)
+	require.Equal(t, "Size", numeric[0].key)
+	require.Equal(t, "Rate", numeric[1].key)
+
+	for _, query := range []string{"filter=Size:gt:ten", "filter=Size:gt", "filter=Size:like:1"} {
+		args.Parse(query)
+		_, _, err = parseSearchQuery(&args)
+		require.Error(t, err, query)
+	}
+}
+
+func TestSearchResult(t *testing.T) {
+	var args fasthttp.Args
+	args.Parse("filter=Size:gt:10&filter=Size:le:100")
+	_, numeric, err := parseSearchQuery(&args)
+	require.NoError(t, err)
+
+	newObject := func(attrs map[string]string) *object.Object {
+		obj := object.New()
+		var list []object.Attribute
+		for k, v := range attrs {
+			attr := object.NewAttribute()
+			attr.SetKey(k)
+			attr.SetValue(v)
+			list = append(list, *attr)
+		}
+		obj.SetAttributes(list...)
+		return obj
+	}
+
+	id := oidtest.ID()
+	s := searchStream{numeric: numeric, attrs: map[string]struct{}{"FileName": {}}}
+	require.True(t, s.needHeader())
+
+	res, ok := s.result(id, newObject(map[string]string{"Size": "100", "FileName": "a.txt", "Other": "x"}))
+	require.True(t, ok)
+	require.Equal(t, searchResult{ObjectID: id.EncodeToString(), Attributes: map[string]string{"FileName": "a.txt"}}, res)
+
+	for _, attrs := range []map[string]string{
+		{"Size": "10"},
+		{"Size": "100.5"},
+		{"Size": "big"},
+		{"FileName": "a.txt"},
+	} {
+		_, ok = s.result(id, newObject(attrs))
+		require.False(t, ok, attrs)
+	}
+
+	s = searchStream{attrs: nil}
+	res, ok = s.result(id, newObject(map[string]string{"A": "1", "B": "2"}))
+	require.True(t, ok)
+	require.Equal(t, map[string]string{"A": "1", "B": "2"}, res.Attributes)
+
+	s = searchStream{attrs: map[string]struct{}{}}
+	require.False(t, s.needHeader())
+	res, ok = s.result(id, nil)
+	require.True(t, ok)
+	require.Equal(t, searchResult{ObjectID: id.EncodeToString()}, res)
+}
@@ -0,0 +1,111 @@
+package downloader
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+)
+
+const (
+	tarFormatPlain = "tar"
+	tarFormatGzip  = "tar.gz"
+
+	// tarFileMode is the mode of files in tar archives, objects don't have
+	// permissions, so the regular file ones are used.
+	tarFileMode = 0644
+)
+
+type tarWriter struct {
+	*tar.Writer
+	gz *gzip.Writer
+	// left is the number of payload bytes of the current file not written
+	// yet.
+	left int64
+}
+
+// tarFormat returns tar archive format by the format query parameter value.
+func tarFormat(name string) (archiveFormat, error) {
+	switch name {
+	case "", tarFormatPlain:
+		return archiveFormat{
+			contentType: "application/x-tar",
+			extension:   ".tar",
+			newWriter: func(w io.Writer) archiveWriter {
+				return &tarWriter{Writer: tar.NewWriter(w)}
+			},
+		}, nil
+	case tarFormatGzip, "tgz":
+		return archiveFormat{
+			contentType: "application/gzip",
+			extension:   ".tar.gz",
+			newWriter: func(w io.Writer) archiveWriter {
+				gz := gzip.NewWriter(w)
+				return &tarWriter{Writer: tar.NewWriter(gz), gz: gz}
+			},
+		}, nil
+	default:
+		return archiveFormat{}, fmt.Errorf("unsupported archive format '%s'", name)
+	}
+}
+
+func (t *tarWriter) createFile(obj *object.Object, name string) (io.Writer, error) {
+	size := int64(obj.PayloadSize())
+	err := t.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     size,
+		Mode:     tarFileMode,
+		ModTime:  archiveModTime(obj),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	t.left = size
+	return t, nil
+}
+
+func (t *tarWriter) Write(p []byte) (int, error) {
+	n, err := t.Writer.Write(p)
+	t.left -= int64(n)
+	return n, err
+}
+
+// padFile fills the rest of the current file with zeros, otherwise tar writer
+// fails all the following files.
+func (t *tarWriter) padFile() error {
+	var zeros [4096]byte
+	for t.left > 0 {
+		chunk := zeros[:]
+		if t.left < int64(len(chunk)) {
+			chunk = chunk[:t.left]
+		}
+		if _, err := t.Write(chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *tarWriter) Flush() error {
+	if err := t.Writer.Flush(); err != nil {
+		return err
+	}
+	if t.gz != nil {
+		return t.gz.Flush()
+	}
+	return nil
+}
+
+func (t *tarWriter) Close() error {
+	if err := t.Writer.Close(); err != nil {
+		return err
+	}
+	if t.gz != nil {
+		return t.gz.Close()
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package downloader
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"strconv"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTarFormat(t *testing.T) {
+	_, err := tarFormat("rar")
+	require.Error(t, err)
+
+	for _, name := range []string{"", tarFormatPlain, tarFormatGzip} {
+		t.Run("format "+name, func(t *testing.T) {
+			format, err := tarFormat(name)
+			require.NoError(t, err)
+
+			payload := []byte("content")
+			modTime := time.Unix(1700000000, 0)
+
+			obj := object.New()
+			obj.SetPayloadSize(uint64(len(payload)))
+			attr := object.NewAttribute()
+			attr.SetKey(object.AttributeTimestamp)
+			attr.SetValue(strconv.FormatInt(modTime.Unix(), 10))
+			obj.SetAttributes(*attr)
+
+			var buf bytes.Buffer
+			aw := format.newWriter(&buf)
+			w, err := aw.createFile(obj, "dir/file.txt")
+			require.NoError(t, err)
+			_, err = w.Write(payload)
+			require.NoError(t, err)
+			require.NoError(t, aw.Flush())
+			require.NoError(t, aw.Close())
+
+			var r io.Reader = &buf
+			if name == tarFormatGzip {
+				r, err = gzip.NewReader(&buf)
+				require.NoError(t, err)
+			}
+
+			tr := tar.NewReader(r)
+			hdr, err := tr.Next()
+			require.NoError(t, err)
+			require.Equal(t, "dir/file.txt", hdr.Name)
+			require.EqualValues(t, len(payload), hdr.Size)
+			require.EqualValues(t, tarFileMode, hdr.Mode)
+			require.True(t, modTime.Equal(hdr.ModTime))
+
+			data, err := io.ReadAll(tr)
+			require.NoError(t, err)
+			require.Equal(t, payload, data)
+
+			_, err = tr.Next()
+			require.ErrorIs(t, err, io.EOF)
+		})
+	}
+}
+
+func TestTarIncompletePayload(t *testing.T) {
+	newObject := func(size int) *object.Object {
+		obj := object.New()
+		obj.SetPayloadSize(uint64(size))
+		return obj
+	}
+
+	for _, name := range []string{tarFormatPlain, tarFormatGzip} {
+		t.Run("format "+name, func(t *testing.T) {
+			format, err := tarFormat(name)
+			require.NoError(t, err)
+
+			var (
+				d        *Downloader
+				buf      bytes.Buffer
+				aw       = format.newWriter(&buf)
+				copyBuf  = make([]byte, 1024)
+				failures []archiveFailure
+				errRead  = errors.New("connection reset")
+				payload  = []byte("content")
+			)
+
+			// payload read fails in the middle
+			err = d.archiveObject(aw, newObject(10000), "failed.txt",
+				io.MultiReader(bytes.NewReader(bytes.Repeat([]byte{1}, 3000)), iotest.ErrReader(errRead)), copyBuf)
+			require.ErrorContains(t, err, errRead.Error())
+			failures = append(failures, archiveFailure{name: "failed.txt", err: err})
+
+			require.NoError(t, d.archiveObject(aw, newObject(len(payload)), "ok.txt", bytes.NewReader(payload), copyBuf))
+
+			// payload is shorter than the header claims
+			err = d.archiveObject(aw, newObject(5), "short.txt", bytes.NewReader([]byte("ab")), copyBuf)
+			require.ErrorContains(t, err, "payload size mismatch")
+			failures = append(failures, archiveFailure{name: "short.txt", err: err})
+
+			require.NoError(t, writeArchiveReport(aw, failures))
+			require.NoError(t, aw.Close())
+
+			var r io.Reader = &buf
+			if name == tarFormatGzip {
+				r, err = gzip.NewReader(&buf)
+				require.NoError(t, err)
+			}
+
+			tr := tar.NewReader(r)
+			for _, expected := range []struct {
+				name string
+				data []byte
+			}{
+				{name: "failed.txt", data: append(bytes.Repeat([]byte{1}, 3000), make([]byte, 7000)...)},
+				{name: "ok.txt", data: payload},
+				{name: "short.txt", data: []byte("ab\x00\x00\x00")},
+				{name: archiveReportName, data: archiveReport(failures)},
+			} {
+				hdr, err := tr.Next()
+				require.NoError(t, err)
+				require.Equal(t, expected.name, hdr.Name)
+
+				data, err := io.ReadAll(tr)
+				require.NoError(t, err)
+				require.Equal(t, expected.data, data, expected.name)
+			}
+
+			_, err = tr.Next()
+			require.ErrorIs(t, err, io.EOF)
+		})
+	}
+}
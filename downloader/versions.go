@@ -0,0 +1,339 @@
+package downloader
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/tokens"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+const (
+	hdrVersionsCount = "X-Versions-Count"
+
+	// versionArg is the query argument selecting the object version by ID.
+	versionArg = "version"
+	// ambiguityArg is the query argument choosing the way the object is
+	// selected if several objects match.
+	ambiguityArg = "ambiguity"
+
+	jsonContentType = "application/json; charset=UTF-8"
+)
+
+// objectVersion is one of the objects sharing the same attribute value.
+type objectVersion struct {
+	id  oid.ID
+	hdr *object.Object
+}
+
+// ambiguityMode is the way the object is selected if several objects match
+// the lookup.
+type ambiguityMode int
+
+const (
+	// ambiguityNewest selects the newest object.
+	ambiguityNewest ambiguityMode = iota
+	// ambiguityFail makes the lookup fail with errAmbiguousObject.
+	ambiguityFail
+	// ambiguityFirst selects the first found object without getting headers
+	// of all the objects.
+	ambiguityFirst
+)
+
+var ambiguityModes = map[string]ambiguityMode{
+	"newest": ambiguityNewest,
+	"fail":   ambiguityFail,
+	"first":  ambiguityFirst,
+}
+
+// errAmbiguousObject is returned if several objects match the lookup and
+// ambiguityFail mode is used.
+var errAmbiguousObject = errors.New("several objects match")
+
+// ambiguityFromQuery returns the ambiguity mode requested with ambiguity
+// query argument, def is returned if it's not set.
+func ambiguityFromQuery(args *fasthttp.Args, def ambiguityMode) (ambiguityMode, error) {
+	val := args.Peek(ambiguityArg)
+	if len(val) == 0 {
+		return def, nil
+	}
+	mode, ok := ambiguityModes[string(val)]
+	if !ok {
+		return def, fmt.Errorf("unknown ambiguity mode '%s', expected newest, fail or first", val)
+	}
+	return mode, nil
+}
+
+// versionInfo is the element of object version list.
+type versionInfo struct {
+	ObjectID        string  `json:"object_id"`
+	Size            uint64  `json:"size"`
+	Timestamp       *int64  `json:"timestamp,omitempty"`
+	CreationEpoch   uint64  `json:"creation_epoch"`
+	OwnerID         string  `json:"owner_id"`
+	ExpirationEpoch *uint64 `json:"expiration_epoch,omitempty"`
+}
+
+func newVersionInfo(v objectVersion) versionInfo {
+	info := versionInfo{
+		ObjectID:      v.id.EncodeToString(),
+		Size:          v.hdr.PayloadSize(),
+		CreationEpoch: v.hdr.CreationEpoch(),
+		OwnerID:       v.hdr.OwnerID().String(),
+	}
+	if ts, ok := objectTimestamp(v.hdr); ok {
+		unix := ts.Unix()
+		info.Timestamp = &unix
+	}
+	if epoch, ok := objectExpirationEpoch(v.hdr); ok {
+		info.ExpirationEpoch = &epoch
+	}
+	return info
+}
+
+// newer checks whether the version is newer than the other one. Versions are
+// ordered by Timestamp attribute presence (objects with it are newer), then by
+// Timestamp values, creation epochs and object IDs, so the order is total and
+// deterministic.
+func (v objectVersion) newer(other objectVersion) bool {
+	ts, ok := objectTimestamp(v.hdr)
+	otherTS, otherOK := objectTimestamp(other.hdr)
+	if ok != otherOK {
+		return ok
+	}
+	if ok && !ts.Equal(otherTS) {
+		return ts.After(otherTS)
+	}
+
+	if epoch, otherEpoch := v.hdr.CreationEpoch(), other.hdr.CreationEpoch(); epoch != otherEpoch {
+		return epoch > otherEpoch
+	}
+
+	return v.id.EncodeToString() > other.id.EncodeToString()
+}
+
+// sortVersions sorts object versions, the newest one goes first.
+func sortVersions(versions []objectVersion) {
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].newer(versions[j])
+	})
+}
+
+// findVersions returns IDs of all objects with the attribute equal to the
+// given value, errObjectNotFound is returned if there are no such objects.
+func (d *Downloader) findVersions(c *fasthttp.RequestCtx, cnrID *cid.ID, key, val string) ([]oid.ID, error) {
+	filters := object.NewSearchFilters()
+	filters.AddFilter(key, val, object.MatchStringEqual)
+
+	return d.findMatchingVersions(c, cnrID, filters)
+}
+
+// findMatchingVersions returns IDs of all objects matching the filters,
+// errObjectNotFound is returned if there are no such objects.
+func (d *Downloader) findMatchingVersions(c *fasthttp.RequestCtx, cnrID *cid.ID, filters object.SearchFilters) ([]oid.ID, error) {
+	res, err := d.searchObjects(c, cnrID, filters)
+	if err != nil {
+		return nil, fmt.Errorf("could not search for objects: %w", err)
+	}
+	defer res.Close()
+
+	var ids []oid.ID
+	if err = res.Iterate(func(id oid.ID) bool {
+		ids = append(ids, id)
+		return false
+	}); err != nil {
+		return nil, fmt.Errorf("read object list failed: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil, errObjectNotFound
+	}
+
+	return ids, nil
+}
+
+// headVersions gets headers of the objects and returns them sorted from the
+// newest to the oldest one. Objects removed after the search are skipped.
+func (d *Downloader) headVersions(c *fasthttp.RequestCtx, cnrID cid.ID, ids []oid.ID) ([]objectVersion, error) {
+	var prm client.PrmObjectHead
+	if btoken := bearerToken(c); btoken != nil {
+		prm.WithBearerToken(*btoken)
+	}
+
+	versions := make([]objectVersion, 0, len(ids))
+	for _, id := range ids {
+		hdr, err := d.pool.ObjectHead(d.appCtx, cnrID, id, d.signer, prm)
+		if err != nil {
+			if errors.Is(err, apistatus.ErrObjectNotFound) || errors.Is(err, apistatus.ErrObjectAlreadyRemoved) {
+				continue
+			}
+			return nil, fmt.Errorf("could not head object %s: %w", id, err)
+		}
+		versions = append(versions, objectVersion{id: id, hdr: hdr})
+	}
+	if len(versions) == 0 {
+		return nil, errObjectNotFound
+	}
+
+	sortVersions(versions)
+	return versions, nil
+}
+
+// latestVersion returns the ID of the newest object of the given ones.
+func (d *Downloader) latestVersion(c *fasthttp.RequestCtx, cnrID cid.ID, ids []oid.ID) (oid.ID, error) {
+	if len(ids) == 1 {
+		return ids[0], nil
+	}
+
+	versions, err := d.headVersions(c, cnrID, ids)
+	if err != nil {
+		return oid.ID{}, err
+	}
+
+	return versions[0].id, nil
+}
+
+// selectVersion returns the ID of the object version requested with version
+// query argument or the one selected according to the ambiguity mode, the
+// number of versions is set to X-Versions-Count response header.
+func (d *Downloader) selectVersion(c *fasthttp.RequestCtx, cnrID cid.ID, ids []oid.ID, mode ambiguityMode) (oid.ID, error) {
+	var (
+		objID oid.ID
+		err   error
+	)
+
+	c.Response.Header.Set(hdrVersionsCount, strconv.Itoa(len(ids)))
+
+	if version := c.QueryArgs().Peek(versionArg); len(version) != 0 {
+		if err = objID.DecodeString(string(version)); err != nil {
+			return oid.ID{}, fmt.Errorf("invalid version: %w", err)
+		}
+		found := false
+		for i := range ids {
+			if ids[i] == objID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return oid.ID{}, errObjectNotFound
+		}
+		return objID, nil
+	}
+
+	switch {
+	case len(ids) == 1 || mode == ambiguityFirst:
+		return ids[0], nil
+	case mode == ambiguityFail:
+		return oid.ID{}, errAmbiguousObject
+	default:
+		return d.latestVersion(c, cnrID, ids)
+	}
+}
+
+// serveVersion serves the object version selected by the request from the
+// objects with the same attribute value.
+func (d *Downloader) serveVersion(c *fasthttp.RequestCtx, log *zap.Logger, cnrID cid.ID, ids []oid.ID, mode ambiguityMode,
+	f func(request, *pool.Pool, oid.Address, user.Signer)) {
+	objID, err := d.selectVersion(c, cnrID, ids, mode)
+	if err != nil {
+		log.Error("could not select object version", zap.Error(err))
+		if errors.Is(err, errObjectNotFound) {
+			response.Error(c, "object not found", fasthttp.StatusNotFound)
+			return
+		}
+		if errors.Is(err, errAmbiguousObject) {
+			response.Error(c, "several objects match, use version or ambiguity argument", fasthttp.StatusConflict)
+			c.Response.Header.Set(hdrVersionsCount, strconv.Itoa(len(ids)))
+			return
+		}
+		response.Error(c, err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	var addr oid.Address
+	addr.SetContainer(cnrID)
+	addr.SetObject(objID)
+
+	req := d.newRequest(c, log)
+	req.cacheControl = d.settings.CachePolicy(cnrID).ByAttribute
+	if c.QueryArgs().Has(versionArg) {
+		// the exact object is requested, so the reply never changes
+		req.cacheControl = d.settings.CachePolicy(cnrID).ByAddress
+	}
+
+	f(*req, d.pool, addr, d.signer)
+}
+
+// ListVersions handles requests for the list of objects sharing the same
+// FilePath (or FileName) attribute, the newest one goes first.
+func (d *Downloader) ListVersions(c *fasthttp.RequestCtx) {
+	scid, _ := c.UserValue("cid").(string)
+	log := d.log.With(zap.String("cid", scid))
+
+	objPath, err := routePath(c)
+	if err != nil {
+		log.Error("invalid object path", zap.Error(err))
+		response.Error(c, err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+	log = log.With(zap.String("path", objPath))
+
+	if objPath == "" {
+		log.Debug("empty object path")
+		response.Error(c, "object not found", fasthttp.StatusNotFound)
+		return
+	}
+
+	cnrID, err := utils.GetContainerID(d.appCtx, scid, d.containerResolver)
+	if err != nil {
+		log.Error("wrong container id", zap.Error(err))
+		response.Error(c, "wrong container id", fasthttp.StatusBadRequest)
+		return
+	}
+
+	if err = tokens.StoreBearerToken(c); err != nil {
+		log.Error("could not fetch and store bearer token", zap.Error(err))
+		response.Error(c, "could not fetch and store bearer token: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	var versions []objectVersion
+	ids, err := d.findVersionsByPath(c, cnrID, objPath)
+	if err == nil {
+		versions, err = d.headVersions(c, *cnrID, ids)
+	}
+	if err != nil {
+		log.Error("could not get object versions", zap.Error(err))
+		if errors.Is(err, errObjectNotFound) {
+			response.Error(c, "object not found", fasthttp.StatusNotFound)
+			return
+		}
+		response.Error(c, err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	list := make([]versionInfo, len(versions))
+	for i := range versions {
+		list[i] = newVersionInfo(versions[i])
+	}
+
+	c.Response.Header.SetContentType(jsonContentType)
+	enc := json.NewEncoder(c)
+	enc.SetIndent("", "\t")
+	if err = enc.Encode(list); err != nil {
+		log.Error("could not encode version list", zap.Error(err))
+	}
+}
@@ -0,0 +1,159 @@
+package downloader
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	oidtest "github.com/nspcc-dev/neofs-sdk-go/object/id/test"
+	usertest "github.com/nspcc-dev/neofs-sdk-go/user/test"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+)
+
+func TestSortVersions(t *testing.T) {
+	newVersion := func(timestamp int64, epoch uint64) objectVersion {
+		obj := object.New()
+		obj.SetCreationEpoch(epoch)
+		if timestamp != 0 {
+			attr := object.NewAttribute()
+			attr.SetKey(object.AttributeTimestamp)
+			attr.SetValue(strconv.FormatInt(timestamp, 10))
+			obj.SetAttributes(*attr)
+		}
+		return objectVersion{id: oidtest.ID(), hdr: obj}
+	}
+
+	var (
+		newest     = newVersion(300, 1)
+		middle     = newVersion(200, 5)
+		noTSNewer  = newVersion(0, 10)
+		noTSOlder  = newVersion(0, 2)
+		oldest     = newVersion(100, 10)
+		sameEpoch1 = newVersion(0, 3)
+		sameEpoch2 = newVersion(0, 3)
+	)
+
+	versions := []objectVersion{oldest, middle, newest}
+	sortVersions(versions)
+	require.Equal(t, []objectVersion{newest, middle, oldest}, versions)
+
+	// objects with Timestamp are newer, creation epoch is compared if it's
+	// missing for both
+	versions = []objectVersion{noTSOlder, middle, noTSNewer, oldest}
+	sortVersions(versions)
+	require.Equal(t, []objectVersion{middle, oldest, noTSNewer, noTSOlder}, versions)
+
+	// ID breaks ties
+	require.NotEqual(t, sameEpoch1.newer(sameEpoch2), sameEpoch2.newer(sameEpoch1))
+
+	// the order is total with mixed Timestamp presence: any permutation is
+	// sorted the same way
+	all := []objectVersion{newest, middle, noTSNewer, noTSOlder, oldest, sameEpoch1, sameEpoch2}
+	for i := range all {
+		for j := range all {
+			if i == j {
+				require.False(t, all[i].newer(all[j]))
+				continue
+			}
+			require.NotEqual(t, all[i].newer(all[j]), all[j].newer(all[i]))
+			for k := range all {
+				if all[i].newer(all[j]) && all[j].newer(all[k]) {
+					require.True(t, all[i].newer(all[k]))
+				}
+			}
+		}
+	}
+
+	expected := make([]objectVersion, len(all))
+	copy(expected, all)
+	sortVersions(expected)
+	for i := 0; i < len(all); i++ {
+		versions = append(append([]objectVersion(nil), all[i:]...), all[:i]...)
+		sortVersions(versions)
+		require.Equal(t, expected, versions)
+	}
+}
+
+func TestVersionInfo(t *testing.T) {
+	owner := usertest.ID(t)
+	obj := object.New()
+	obj.SetOwnerID(&owner)
+	obj.SetPayloadSize(42)
+	obj.SetCreationEpoch(7)
+
+	v := objectVersion{id: oidtest.ID(), hdr: obj}
+	data, err := json.Marshal(newVersionInfo(v))
+	require.NoError(t, err)
+	require.JSONEq(t, `{"object_id":"`+v.id.EncodeToString()+`","size":42,"creation_epoch":7,"owner_id":"`+
+		owner.String()+`"}`, string(data))
+
+	var ts, exp object.Attribute
+	ts.SetKey(object.AttributeTimestamp)
+	ts.SetValue("1700000000")
+	exp.SetKey(object.AttributeExpirationEpoch)
+	exp.SetValue("100")
+	obj.SetAttributes(ts, exp)
+
+	info := newVersionInfo(v)
+	require.NotNil(t, info.Timestamp)
+	require.EqualValues(t, 1700000000, *info.Timestamp)
+	require.NotNil(t, info.ExpirationEpoch)
+	require.EqualValues(t, 100, *info.ExpirationEpoch)
+}
+
+func TestAmbiguityFromQuery(t *testing.T) {
+	var args fasthttp.Args
+	mode, err := ambiguityFromQuery(&args, ambiguityFail)
+	require.NoError(t, err)
+	require.Equal(t, ambiguityFail, mode)
+
+	for val, exp := range ambiguityModes {
+		args.Parse("ambiguity=" + val)
+		mode, err = ambiguityFromQuery(&args, ambiguityNewest)
+		require.NoError(t, err)
+		require.Equal(t, exp, mode)
+	}
+
+	args.Parse("ambiguity=random")
+	_, err = ambiguityFromQuery(&args, ambiguityNewest)
+	require.Error(t, err)
+}
+
+func TestSelectVersion(t *testing.T) {
+	var (
+		d   Downloader
+		ids = []oid.ID{oidtest.ID(), oidtest.ID()}
+	)
+
+	newContext := func(query string) *fasthttp.RequestCtx {
+		var c fasthttp.RequestCtx
+		c.Request.SetRequestURI("/get_by_attribute/cid/key/val" + query)
+		return &c
+	}
+
+	c := newContext("")
+	id, err := d.selectVersion(c, cidtest.ID(), ids[:1], ambiguityFail)
+	require.NoError(t, err)
+	require.Equal(t, ids[0], id)
+	require.Equal(t, "1", string(c.Response.Header.Peek(hdrVersionsCount)))
+
+	c = newContext("")
+	_, err = d.selectVersion(c, cidtest.ID(), ids, ambiguityFail)
+	require.ErrorIs(t, err, errAmbiguousObject)
+	require.Equal(t, "2", string(c.Response.Header.Peek(hdrVersionsCount)))
+
+	id, err = d.selectVersion(newContext(""), cidtest.ID(), ids, ambiguityFirst)
+	require.NoError(t, err)
+	require.Equal(t, ids[0], id)
+
+	id, err = d.selectVersion(newContext("?version="+ids[1].EncodeToString()), cidtest.ID(), ids, ambiguityFail)
+	require.NoError(t, err)
+	require.Equal(t, ids[1], id)
+
+	_, err = d.selectVersion(newContext("?version="+oidtest.ID().EncodeToString()), cidtest.ID(), ids, ambiguityFail)
+	require.ErrorIs(t, err, errObjectNotFound)
+}
@@ -0,0 +1,205 @@
+package downloader
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// virtualHostKey is the request user value key of the container selected by
+// Host header.
+const virtualHostKey = "__virtual_host_container"
+
+const (
+	// virtualHostCacheTTL is the time container resolution results of virtual
+	// hosts are cached for.
+	virtualHostCacheTTL = time.Minute
+	// virtualHostCacheSize limits the number of cached results, Host header is
+	// controlled by clients.
+	virtualHostCacheSize = 1024
+)
+
+// gatewayRoutePrefixes are the paths of gateway routes which are never served
+// from containers of hosts resolved with ResolveHosts (configured hosts serve
+// all the paths).
+var gatewayRoutePrefixes = []string{
+	"/upload/",
+	"/get/",
+	"/get_by_attribute/",
+	"/zip/",
+	"/tar/",
+	"/site/",
+	"/search/",
+	"/list/",
+	"/versions/",
+}
+
+// isGatewayRoute checks whether the path belongs to one of gateway routes.
+func isGatewayRoute(path string) bool {
+	for _, prefix := range gatewayRoutePrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostCache caches container IDs resolved from virtual host container names.
+type hostCache struct {
+	mu      sync.Mutex
+	entries map[string]hostCacheEntry
+}
+
+type hostCacheEntry struct {
+	// cnrID is nil if the name is not resolved.
+	cnrID   *cid.ID
+	expires time.Time
+}
+
+// get returns the cached resolution result of the container name and false if
+// there is no such result or it's expired.
+func (c *hostCache) get(name string, now time.Time) (*cid.ID, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[name]
+	if !ok || now.After(entry.expires) {
+		return nil, false
+	}
+	return entry.cnrID, true
+}
+
+// put caches the resolution result of the container name, expired entries are
+// removed if the cache is full (and all the entries if there are no expired
+// ones).
+func (c *hostCache) put(name string, cnrID *cid.ID, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= virtualHostCacheSize {
+		for k, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	if c.entries == nil || len(c.entries) >= virtualHostCacheSize {
+		c.entries = make(map[string]hostCacheEntry)
+	}
+	c.entries[name] = hostCacheEntry{cnrID: cnrID, expires: now.Add(virtualHostCacheTTL)}
+}
+
+// VirtualHosts contains settings of container selection by Host header.
+type VirtualHosts struct {
+	// Hosts maps host names to container IDs or names.
+	Hosts map[string]string
+	// Domains are parent domains, the subdomains of which are container names
+	// (e.g. {name}.gw.example.org).
+	Domains []string
+	// ResolveHosts enables resolving of other host names as container names.
+	ResolveHosts bool
+}
+
+// container returns container ID or name for the host and true if the host
+// is a configured one, otherwise the host itself is returned if it has to be
+// resolved.
+func (v *VirtualHosts) container(host string) (string, bool) {
+	if name, ok := v.Hosts[host]; ok {
+		return name, true
+	}
+	for _, domain := range v.Domains {
+		if name := strings.TrimSuffix(host, "."+domain); name != host && name != "" {
+			return name, true
+		}
+	}
+	if v.ResolveHosts {
+		return host, false
+	}
+	return "", false
+}
+
+// normalizeHost removes port and trailing dot from the host and makes it
+// lowercase.
+func normalizeHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
+
+// IsVirtualHost checks whether GET or HEAD request is sent to the virtual host
+// mapped to a container, such requests have to be handled by
+// DownloadVirtualHost. Container resolution results are cached.
+func (d *Downloader) IsVirtualHost(c *fasthttp.RequestCtx) bool {
+	vhosts := d.settings.VirtualHosts()
+	if vhosts == nil || !(c.IsGet() || c.IsHead()) {
+		return false
+	}
+
+	host := normalizeHost(string(c.Host()))
+	name, configured := vhosts.container(host)
+	if name == "" || !configured && isGatewayRoute(string(c.Path())) {
+		return false
+	}
+
+	cnrID := d.resolveVirtualHost(host, name)
+	if cnrID == nil && !configured {
+		// regular request to the gateway itself
+		return false
+	}
+
+	c.SetUserValue(virtualHostKey, cnrID)
+	return true
+}
+
+// resolveVirtualHost returns the container ID by its name or nil if it can't
+// be resolved.
+func (d *Downloader) resolveVirtualHost(host, name string) *cid.ID {
+	now := time.Now()
+	if cnrID, ok := d.hostCache.get(name, now); ok {
+		return cnrID
+	}
+
+	cnrID, err := utils.GetContainerID(d.appCtx, name, d.containerResolver)
+	if err != nil {
+		d.log.Debug("could not resolve virtual host container", zap.String("host", host),
+			zap.String("container", name), zap.Error(err))
+		cnrID = nil
+	}
+
+	d.hostCache.put(name, cnrID, now)
+	return cnrID
+}
+
+// DownloadVirtualHost handles GET and HEAD requests to virtual hosts, the
+// request path is served from the container the same way as website one.
+func (d *Downloader) DownloadVirtualHost(c *fasthttp.RequestCtx) {
+	var (
+		host     = normalizeHost(string(c.Host()))
+		sitePath = string(c.Path())
+		log      = d.log.With(zap.String("host", host), zap.String("path", sitePath))
+	)
+
+	cnrID, _ := c.UserValue(virtualHostKey).(*cid.ID)
+	if cnrID == nil {
+		log.Error("unknown virtual host container")
+		response.Error(c, "container not found", fasthttp.StatusNotFound)
+		return
+	}
+	log = log.With(zap.Stringer("cid", cnrID))
+
+	cfg, _ := d.settings.Website(*cnrID)
+	f := request.receiveFile
+	if c.IsHead() {
+		f = request.headObject
+	}
+
+	d.serveWebsite(c, log, *cnrID, cfg, "/", sitePath, f)
+}
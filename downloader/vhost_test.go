@@ -0,0 +1,109 @@
+package downloader
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeHost(t *testing.T) {
+	for host, expected := range map[string]string{
+		"docs.example.org":       "docs.example.org",
+		"Docs.Example.org:8080":  "docs.example.org",
+		"docs.example.org.":      "docs.example.org",
+		"docs.example.org.:8080": "docs.example.org",
+		"[::1]:8080":             "::1",
+	} {
+		require.Equal(t, expected, normalizeHost(host), host)
+	}
+}
+
+func TestVirtualHostsContainer(t *testing.T) {
+	vhosts := VirtualHosts{
+		Hosts:   map[string]string{"docs.example.org": "docs"},
+		Domains: []string{"gw.example.org"},
+	}
+
+	for host, expected := range map[string]string{
+		"docs.example.org":        "docs",
+		"site.gw.example.org":     "site",
+		"my.site.gw.example.org":  "my.site",
+		"gw.example.org":          "",
+		"site.xgw.example.org":    "",
+		"other.example.org":       "",
+		"site.gw.example.org.com": "",
+	} {
+		name, configured := vhosts.container(host)
+		require.Equal(t, expected, name, host)
+		require.Equal(t, expected != "", configured, host)
+	}
+
+	vhosts.ResolveHosts = true
+	name, configured := vhosts.container("other.example.org")
+	require.Equal(t, "other.example.org", name)
+	require.False(t, configured)
+}
+
+func TestIsGatewayRoute(t *testing.T) {
+	for path, expected := range map[string]bool{
+		"/get/cid/oid":          true,
+		"/zip/cid/prefix":       true,
+		"/versions/cid/a/b":     true,
+		"/":                     false,
+		"/index.html":           false,
+		"/getting-started.html": false,
+		"/docs/get/index.html":  false,
+	} {
+		require.Equal(t, expected, isGatewayRoute(path), path)
+	}
+}
+
+func TestHostCache(t *testing.T) {
+	var (
+		cache hostCache
+		cnrID = cidtest.ID()
+		now   = time.Now()
+	)
+
+	_, ok := cache.get("site", now)
+	require.False(t, ok)
+
+	cache.put("site", &cnrID, now)
+	cache.put("unknown", nil, now)
+
+	res, ok := cache.get("site", now.Add(virtualHostCacheTTL))
+	require.True(t, ok)
+	require.Equal(t, cnrID, *res)
+
+	res, ok = cache.get("unknown", now)
+	require.True(t, ok)
+	require.Nil(t, res)
+
+	_, ok = cache.get("site", now.Add(virtualHostCacheTTL+time.Second))
+	require.False(t, ok)
+
+	t.Run("eviction", func(t *testing.T) {
+		var cache hostCache
+		for i := 0; i < virtualHostCacheSize; i++ {
+			cache.put(strconv.Itoa(i), nil, now)
+		}
+		require.Len(t, cache.entries, virtualHostCacheSize)
+
+		cache.put("site", &cnrID, now)
+		require.Len(t, cache.entries, 1)
+
+		later := now.Add(virtualHostCacheTTL + time.Second)
+		for i := 0; i < virtualHostCacheSize-1; i++ {
+			cache.put(strconv.Itoa(i), nil, later)
+		}
+		cache.put("new", nil, later)
+		require.Len(t, cache.entries, virtualHostCacheSize)
+		_, ok := cache.get("site", later)
+		require.False(t, ok)
+		_, ok = cache.get("new", later)
+		require.True(t, ok)
+	})
+}
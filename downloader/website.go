@@ -0,0 +1,261 @@
+package downloader
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neofs-http-gw/response"
+	"github.com/nspcc-dev/neofs-http-gw/tokens"
+	"github.com/nspcc-dev/neofs-http-gw/utils"
+	apistatus "github.com/nspcc-dev/neofs-sdk-go/client/status"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	oid "github.com/nspcc-dev/neofs-sdk-go/object/id"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+	"github.com/nspcc-dev/neofs-sdk-go/user"
+	"github.com/valyala/fasthttp"
+	"go.uber.org/zap"
+)
+
+// ContainerAttributeWebsite is the container attribute enabling website mode
+// for the container with the global website settings if it's set to true.
+const ContainerAttributeWebsite = "Website"
+
+const (
+	// websiteCacheTTL is the time Website container attribute values are
+	// cached for.
+	websiteCacheTTL = time.Minute
+	// websiteCacheSize limits the number of cached attribute values.
+	websiteCacheSize = 1024
+)
+
+// websiteCache caches website mode state of containers not listed in the
+// configuration.
+type websiteCache struct {
+	mu      sync.Mutex
+	entries map[cid.ID]websiteCacheEntry
+}
+
+type websiteCacheEntry struct {
+	enabled bool
+	expires time.Time
+}
+
+// get returns the cached website mode state of the container and false if
+// there is no such state or it's expired.
+func (c *websiteCache) get(cnrID cid.ID, now time.Time) (bool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[cnrID]
+	if !ok || now.After(entry.expires) {
+		return false, false
+	}
+	return entry.enabled, true
+}
+
+// put caches website mode state of the container, expired entries are removed
+// if the cache is full (and all the entries if there are no expired ones).
+func (c *websiteCache) put(cnrID cid.ID, enabled bool, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= websiteCacheSize {
+		for k, entry := range c.entries {
+			if now.After(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	if c.entries == nil || len(c.entries) >= websiteCacheSize {
+		c.entries = make(map[cid.ID]websiteCacheEntry)
+	}
+	c.entries[cnrID] = websiteCacheEntry{enabled: enabled, expires: now.Add(websiteCacheTTL)}
+}
+
+// WebsiteConfig contains settings of the static website served from container
+// objects by FilePath attribute.
+type WebsiteConfig struct {
+	// IndexDocument is the name of the document served for directory paths,
+	// empty value disables index documents.
+	IndexDocument string
+	// ErrorDocument is the path of the document served with 404 status code
+	// for unknown paths, empty value means plain 404 reply.
+	ErrorDocument string
+	// SPAFallback makes the root index document served for unknown paths, so
+	// that single page applications can handle routing themselves.
+	SPAFallback bool
+}
+
+type websites struct {
+	global     WebsiteConfig
+	containers map[cid.ID]WebsiteConfig
+}
+
+// website returns the website settings of the container and false if website
+// mode is enabled for it neither in the configuration nor by the container
+// attribute. Container attribute values are cached.
+func (d *Downloader) website(cnrID cid.ID) (WebsiteConfig, bool, error) {
+	cfg, configured := d.settings.Website(cnrID)
+	if configured {
+		return cfg, true, nil
+	}
+
+	now := time.Now()
+	if enabled, ok := d.websiteCache.get(cnrID, now); ok {
+		return cfg, enabled, nil
+	}
+
+	cnr, err := d.getContainer(cnrID)
+	if err != nil {
+		return WebsiteConfig{}, false, err
+	}
+	enabled, _ := strconv.ParseBool(cnr.Attribute(ContainerAttributeWebsite))
+	d.websiteCache.put(cnrID, enabled, now)
+
+	return cfg, enabled, nil
+}
+
+// DownloadWebsite handles GET requests of static website documents.
+func (d *Downloader) DownloadWebsite(c *fasthttp.RequestCtx) {
+	d.byWebsitePath(c, request.receiveFile)
+}
+
+// HeadWebsite handles HEAD requests of static website documents.
+func (d *Downloader) HeadWebsite(c *fasthttp.RequestCtx) {
+	d.byWebsitePath(c, request.headObject)
+}
+
+// byWebsitePath is a wrapper similar to byAddress for /site/{cid}/{path}
+// route.
+func (d *Downloader) byWebsitePath(c *fasthttp.RequestCtx, f func(request, *pool.Pool, oid.Address, user.Signer)) {
+	scid, _ := c.UserValue("cid").(string)
+	log := d.log.With(zap.String("cid", scid))
+
+	sitePath, err := routePath(c)
+	if err != nil {
+		log.Error("invalid website path", zap.Error(err))
+		response.Error(c, err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+	log = log.With(zap.String("path", sitePath))
+
+	cnrID, err := utils.GetContainerID(d.appCtx, scid, d.containerResolver)
+	if err != nil {
+		log.Error("wrong container id", zap.Error(err))
+		response.Error(c, "wrong container id", fasthttp.StatusBadRequest)
+		return
+	}
+
+	cfg, ok, err := d.website(*cnrID)
+	if err != nil {
+		log.Error("could not get container", zap.Error(err))
+		if errors.Is(err, apistatus.ErrContainerNotFound) {
+			response.Error(c, "Not Found", fasthttp.StatusNotFound)
+			return
+		}
+		response.Error(c, "could not get container: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+	if !ok {
+		log.Debug("website mode is disabled for container")
+		response.Error(c, "website mode is disabled for container", fasthttp.StatusNotFound)
+		return
+	}
+
+	d.serveWebsite(c, log, *cnrID, cfg, "/site/"+scid+"/", sitePath, f)
+}
+
+// serveWebsite serves the document of the website by its path, base is the
+// URL path of the website root used for redirects.
+func (d *Downloader) serveWebsite(c *fasthttp.RequestCtx, log *zap.Logger, cnrID cid.ID, cfg WebsiteConfig, base, sitePath string,
+	f func(request, *pool.Pool, oid.Address, user.Signer)) {
+	if err := tokens.StoreBearerToken(c); err != nil {
+		log.Error("could not fetch and store bearer token", zap.Error(err))
+		response.Error(c, "could not fetch and store bearer token: "+err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	sitePath = strings.TrimLeft(sitePath, "/")
+	isDir := sitePath == "" || strings.HasSuffix(sitePath, "/")
+	if isDir {
+		sitePath += cfg.IndexDocument
+	}
+
+	objID, err := d.findWebsiteDocument(c, cnrID, sitePath)
+	if err == nil {
+		d.serveWebsiteDocument(c, log, cnrID, objID, f)
+		return
+	}
+	if !errors.Is(err, errObjectNotFound) {
+		log.Error("could not find website document", zap.Error(err))
+		response.Error(c, err.Error(), fasthttp.StatusBadRequest)
+		return
+	}
+
+	if !isDir && cfg.IndexDocument != "" {
+		// directory requested without trailing slash, redirect to make
+		// relative links of its index document work
+		if _, err = d.findWebsiteDocument(c, cnrID, sitePath+"/"+cfg.IndexDocument); err == nil {
+			location := (&url.URL{Path: base + sitePath + "/"}).EscapedPath()
+			if query := c.URI().QueryString(); len(query) != 0 {
+				location += "?" + string(query)
+			}
+			c.Redirect(location, fasthttp.StatusFound)
+			return
+		}
+	}
+
+	var (
+		fallback string
+		status   = fasthttp.StatusNotFound
+	)
+	switch {
+	case cfg.SPAFallback && cfg.IndexDocument != "":
+		fallback, status = cfg.IndexDocument, fasthttp.StatusOK
+	case cfg.ErrorDocument != "":
+		fallback = strings.TrimLeft(cfg.ErrorDocument, "/")
+	}
+
+	if fallback != "" {
+		if objID, err = d.findWebsiteDocument(c, cnrID, fallback); err == nil {
+			log.Debug("serve website fallback document", zap.String("document", fallback))
+			// the fallback document is not the requested one, so range and
+			// conditional requests can't be applied to it
+			for _, h := range []string{fasthttp.HeaderRange, fasthttp.HeaderIfRange, fasthttp.HeaderIfMatch,
+				fasthttp.HeaderIfNoneMatch, fasthttp.HeaderIfModifiedSince, fasthttp.HeaderIfUnmodifiedSince} {
+				c.Request.Header.Del(h)
+			}
+			c.Response.SetStatusCode(status)
+			d.serveWebsiteDocument(c, log, cnrID, objID, f)
+			return
+		}
+		log.Error("could not find website fallback document", zap.String("document", fallback), zap.Error(err))
+	}
+
+	response.Error(c, "Not Found", fasthttp.StatusNotFound)
+}
+
+// findWebsiteDocument returns the ID of website document by its path.
+func (d *Downloader) findWebsiteDocument(c *fasthttp.RequestCtx, cnrID cid.ID, docPath string) (oid.ID, error) {
+	if docPath == "" || strings.HasSuffix(docPath, "/") {
+		return oid.ID{}, errObjectNotFound
+	}
+	return d.findObject(c, &cnrID, object.AttributeFilePath, docPath)
+}
+
+func (d *Downloader) serveWebsiteDocument(c *fasthttp.RequestCtx, log *zap.Logger, cnrID cid.ID, objID oid.ID,
+	f func(request, *pool.Pool, oid.Address, user.Signer)) {
+	var addr oid.Address
+	addr.SetContainer(cnrID)
+	addr.SetObject(objID)
+
+	req := d.newRequest(c, log)
+	req.cacheControl = d.settings.CachePolicy(cnrID).ByAttribute
+
+	f(*req, d.pool, addr, d.signer)
+}
@@ -0,0 +1,60 @@
+package downloader
+
+import (
+	"testing"
+	"time"
+
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
+	cidtest "github.com/nspcc-dev/neofs-sdk-go/container/id/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebsiteSettings(t *testing.T) {
+	var settings Settings
+	cnrID := cidtest.ID()
+
+	_, ok := settings.Website(cnrID)
+	require.False(t, ok)
+
+	global := WebsiteConfig{IndexDocument: "index.html", ErrorDocument: "404.html"}
+	spa := WebsiteConfig{IndexDocument: "index.html", SPAFallback: true}
+	settings.SetWebsites(global, map[cid.ID]WebsiteConfig{cnrID: spa})
+
+	cfg, ok := settings.Website(cnrID)
+	require.True(t, ok)
+	require.Equal(t, spa, cfg)
+
+	cfg, ok = settings.Website(cidtest.ID())
+	require.False(t, ok)
+	require.Equal(t, global, cfg)
+}
+
+func TestWebsiteCache(t *testing.T) {
+	var (
+		cache          websiteCache
+		enabled, other = cidtest.ID(), cidtest.ID()
+		now            = time.Now()
+	)
+
+	_, ok := cache.get(enabled, now)
+	require.False(t, ok)
+
+	cache.put(enabled, true, now)
+	cache.put(other, false, now)
+
+	res, ok := cache.get(enabled, now.Add(websiteCacheTTL))
+	require.True(t, ok)
+	require.True(t, res)
+
+	res, ok = cache.get(other, now)
+	require.True(t, ok)
+	require.False(t, res)
+
+	_, ok = cache.get(enabled, now.Add(websiteCacheTTL+time.Second))
+	require.False(t, ok)
+
+	for i := 0; i < websiteCacheSize; i++ {
+		cache.put(cidtest.ID(), true, now)
+	}
+	require.LessOrEqual(t, len(cache.entries), websiteCacheSize)
+}
@@ -0,0 +1,96 @@
+package downloader
+
+import (
+	"archive/zip"
+	"hash/crc32"
+	"io"
+	"unicode/utf8"
+
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+)
+
+// zipFlagUTF8 is general purpose bit 11 (language encoding flag), it means
+// that the file name is encoded with UTF-8.
+const zipFlagUTF8 = 0x800
+
+type zipWriter struct {
+	*zip.Writer
+	method uint16
+}
+
+func zipFormat(compression bool) archiveFormat {
+	method := zip.Store
+	if compression {
+		method = zip.Deflate
+	}
+
+	return archiveFormat{
+		contentType: "application/zip",
+		extension:   ".zip",
+		newWriter: func(w io.Writer) archiveWriter {
+			return zipWriter{Writer: zip.NewWriter(w), method: method}
+		},
+	}
+}
+
+func (z zipWriter) fileHeader(obj *object.Object, name string) *zip.FileHeader {
+	fh := &zip.FileHeader{
+		Name:     name,
+		Method:   z.method,
+		Modified: archiveModTime(obj).UTC(),
+	}
+	if !isASCII(name) {
+		fh.Flags |= zipFlagUTF8
+	}
+	return fh
+}
+
+// createFile adds the file which payload is streamed, its CRC-32 and sizes
+// are unknown until the payload is written, so they're put into the data
+// descriptor following the data and into the central directory.
+func (z zipWriter) createFile(obj *object.Object, name string) (io.Writer, error) {
+	// archive/zip switches to Zip64 records by itself when the archive has
+	// more than 65535 files or its sizes and offsets exceed 4 GiB.
+	return z.CreateHeader(z.fileHeader(obj, name))
+}
+
+// writeFile adds the file which payload is in memory. Stored files are
+// written with CRC-32 and sizes in the local file header and without the data
+// descriptor, compressed size of deflated ones is unknown in advance, so they
+// are written the same way as streamed ones.
+func (z zipWriter) writeFile(obj *object.Object, name string, payload []byte) error {
+	var (
+		w   io.Writer
+		err error
+		fh  = z.fileHeader(obj, name)
+	)
+	if z.method == zip.Store {
+		// CreateRaw doesn't fill the fields CreateHeader derives from the
+		// others
+		fh.CreatorVersion = zipVersion20
+		fh.ReaderVersion = zipVersion20
+		fh.ModifiedDate, fh.ModifiedTime = msDosTime(fh.Modified)
+		fh.Extra = appendExtTime(fh.Extra, fh.Modified)
+		fh.CRC32 = crc32.ChecksumIEEE(payload)
+		fh.CompressedSize64 = uint64(len(payload))
+		fh.UncompressedSize64 = uint64(len(payload))
+		w, err = z.CreateRaw(fh)
+	} else {
+		w, err = z.CreateHeader(fh)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(payload)
+	return err
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
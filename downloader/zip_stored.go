@@ -0,0 +1,380 @@
+package downloader
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"math"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+)
+
+// Layout of the zip archive written by storedZipWriter, see APPNOTE.TXT.
+const (
+	zipLocalHeaderLen      = 30
+	zipCentralHeaderLen    = 46
+	zipDataDescriptorLen   = 16
+	zipDataDescriptor64Len = 24
+	zipEndLen              = 22
+	zip64EndLen            = 56
+	zip64LocatorLen        = 20
+
+	// zipExtTimeLen is the length of extended timestamp extra field with
+	// modification time only.
+	zipExtTimeLen = 9
+	// zip64LocalExtraLen is the length of Zip64 extra field of local header
+	// with both sizes.
+	zip64LocalExtraLen = 20
+	// zip64CentralExtraLen is the length of Zip64 extra field of central
+	// directory header with both sizes and local header offset.
+	zip64CentralExtraLen = 28
+
+	zipLocalHeaderSignature   = 0x04034b50
+	zipCentralHeaderSignature = 0x02014b50
+	zipDataDescriptorSig      = 0x08074b50
+	zipEndSignature           = 0x06054b50
+	zip64EndSignature         = 0x06064b50
+	zip64LocatorSignature     = 0x07064b50
+
+	zipExtTimeID = 0x5455
+	zip64ExtraID = 0x0001
+
+	zipVersion20 = 20
+	zipVersion45 = 45
+
+	// zipFlagDataDescriptor means that CRC-32 and sizes follow file data.
+	zipFlagDataDescriptor = 0x8
+
+	zipUint16Max = math.MaxUint16
+	zipUint32Max = math.MaxUint32
+)
+
+var errZipNameTooLong = errors.New("file name is too long")
+
+// zipDOSEpoch is used as modification time of files without Timestamp in
+// stored zip archives, so that they're the same for all the requests.
+var zipDOSEpoch = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// archiveFile is the name and size of the file in archive.
+type archiveFile struct {
+	name string
+	size uint64
+}
+
+// storedZipFile is the file written to the stored zip archive.
+type storedZipFile struct {
+	name    string
+	modTime time.Time
+	size    uint64
+	offset  uint64
+	zip64   bool
+	crc     uint32
+}
+
+// isZip64 checks whether the file requires Zip64 extensions.
+func isZip64(size, offset uint64) bool {
+	return size >= zipUint32Max || offset >= zipUint32Max
+}
+
+// storedZipSize returns the exact size of zip archive written by
+// storedZipWriter for the given files.
+func storedZipSize(files []archiveFile) (uint64, error) {
+	var (
+		offset   uint64
+		cdSize   uint64
+		useZip64 bool
+	)
+
+	for _, f := range files {
+		if len(f.name) > zipUint16Max {
+			return 0, fmt.Errorf("%w: '%s'", errZipNameTooLong, f.name)
+		}
+
+		zip64 := isZip64(f.size, offset)
+		nameLen := uint64(len(f.name))
+
+		offset += zipLocalHeaderLen + nameLen + zipExtTimeLen + f.size + zipDataDescriptorLen
+		cdSize += zipCentralHeaderLen + nameLen + zipExtTimeLen
+		if zip64 {
+			offset += zip64LocalExtraLen + zipDataDescriptor64Len - zipDataDescriptorLen
+			cdSize += zip64CentralExtraLen
+			useZip64 = true
+		}
+	}
+
+	size := offset + cdSize + zipEndLen
+	if useZip64 || len(files) >= zipUint16Max || cdSize >= zipUint32Max || offset >= zipUint32Max {
+		size += zip64EndLen + zip64LocatorLen
+	}
+
+	return size, nil
+}
+
+// storedZipWriter writes zip archive without compression. Unlike archive/zip
+// its layout is completely defined by file names and sizes, so the archive
+// size can be calculated in advance by storedZipSize. File sizes are taken
+// from object headers and must match the written data.
+type storedZipWriter struct {
+	w      io.Writer
+	offset uint64
+	files  []storedZipFile
+
+	// current file state
+	crc     hash.Hash32
+	written uint64
+}
+
+func storedZipFormat() archiveFormat {
+	return archiveFormat{
+		contentType: "application/zip",
+		extension:   ".zip",
+		newWriter: func(w io.Writer) archiveWriter {
+			return &storedZipWriter{w: w}
+		},
+		size: storedZipSize,
+	}
+}
+
+func (z *storedZipWriter) write(p []byte) error {
+	n, err := z.w.Write(p)
+	z.offset += uint64(n)
+	return err
+}
+
+func (z *storedZipWriter) createFile(obj *object.Object, name string) (io.Writer, error) {
+	if err := z.closeFile(); err != nil {
+		return nil, err
+	}
+	if len(name) > zipUint16Max {
+		return nil, fmt.Errorf("%w: '%s'", errZipNameTooLong, name)
+	}
+
+	modTime, ok := objectTimestamp(obj)
+	if !ok {
+		modTime = zipDOSEpoch
+	}
+
+	f := storedZipFile{
+		name:    name,
+		modTime: modTime,
+		size:    obj.PayloadSize(),
+		offset:  z.offset,
+	}
+	f.zip64 = isZip64(f.size, f.offset)
+
+	extraLen := zipExtTimeLen
+	version := uint16(zipVersion20)
+	if f.zip64 {
+		extraLen += zip64LocalExtraLen
+		version = zipVersion45
+	}
+
+	buf := make([]byte, zipLocalHeaderLen, zipLocalHeaderLen+len(name)+extraLen)
+	date, tm := msDosTime(f.modTime)
+	binary.LittleEndian.PutUint32(buf[0:], zipLocalHeaderSignature)
+	binary.LittleEndian.PutUint16(buf[4:], version)
+	binary.LittleEndian.PutUint16(buf[6:], zipFileFlags(name))
+	binary.LittleEndian.PutUint16(buf[8:], 0) // store method
+	binary.LittleEndian.PutUint16(buf[10:], tm)
+	binary.LittleEndian.PutUint16(buf[12:], date)
+	// CRC-32 and sizes are in the data descriptor
+	if f.zip64 {
+		binary.LittleEndian.PutUint32(buf[18:], zipUint32Max)
+		binary.LittleEndian.PutUint32(buf[22:], zipUint32Max)
+	}
+	binary.LittleEndian.PutUint16(buf[26:], uint16(len(name)))
+	binary.LittleEndian.PutUint16(buf[28:], uint16(extraLen))
+	buf = append(buf, name...)
+	buf = appendExtTime(buf, f.modTime)
+	if f.zip64 {
+		// sizes are zero, the actual ones are in the data descriptor
+		buf = binary.LittleEndian.AppendUint16(buf, zip64ExtraID)
+		buf = binary.LittleEndian.AppendUint16(buf, 16)
+		buf = append(buf, make([]byte, 16)...)
+	}
+
+	if err := z.write(buf); err != nil {
+		return nil, err
+	}
+
+	z.files = append(z.files, f)
+	z.crc = crc32.NewIEEE()
+	z.written = 0
+
+	return storedZipFileWriter{z}, nil
+}
+
+type storedZipFileWriter struct {
+	z *storedZipWriter
+}
+
+func (w storedZipFileWriter) Write(p []byte) (int, error) {
+	z := w.z
+	if f := z.files[len(z.files)-1]; z.written+uint64(len(p)) > f.size {
+		return 0, fmt.Errorf("file '%s' exceeds its size %d", f.name, f.size)
+	}
+
+	n, err := z.w.Write(p)
+	z.offset += uint64(n)
+	z.written += uint64(n)
+	_, _ = z.crc.Write(p[:n])
+	return n, err
+}
+
+// closeFile writes the data descriptor of the current file.
+func (z *storedZipWriter) closeFile() error {
+	if z.crc == nil {
+		return nil
+	}
+
+	f := &z.files[len(z.files)-1]
+	if z.written != f.size {
+		return fmt.Errorf("file '%s' size mismatch: expected %d, written %d", f.name, f.size, z.written)
+	}
+	f.crc = z.crc.Sum32()
+	z.crc = nil
+
+	buf := binary.LittleEndian.AppendUint32(nil, zipDataDescriptorSig)
+	buf = binary.LittleEndian.AppendUint32(buf, f.crc)
+	if f.zip64 {
+		buf = binary.LittleEndian.AppendUint64(buf, f.size)
+		buf = binary.LittleEndian.AppendUint64(buf, f.size)
+	} else {
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(f.size))
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(f.size))
+	}
+
+	return z.write(buf)
+}
+
+// Flush implements archiveWriter, the data is written directly to the
+// underlying writer.
+func (z *storedZipWriter) Flush() error {
+	return nil
+}
+
+// Close writes the central directory.
+func (z *storedZipWriter) Close() error {
+	if err := z.closeFile(); err != nil {
+		return err
+	}
+
+	start := z.offset
+	useZip64 := false
+
+	for _, f := range z.files {
+		extraLen := zipExtTimeLen
+		version := uint16(zipVersion20)
+		size32, offset32 := uint32(f.size), uint32(f.offset)
+		if f.zip64 {
+			extraLen += zip64CentralExtraLen
+			version = zipVersion45
+			size32, offset32 = zipUint32Max, zipUint32Max
+			useZip64 = true
+		}
+
+		buf := make([]byte, zipCentralHeaderLen, zipCentralHeaderLen+len(f.name)+extraLen)
+		date, tm := msDosTime(f.modTime)
+		binary.LittleEndian.PutUint32(buf[0:], zipCentralHeaderSignature)
+		binary.LittleEndian.PutUint16(buf[4:], version) // made by
+		binary.LittleEndian.PutUint16(buf[6:], version) // needed to extract
+		binary.LittleEndian.PutUint16(buf[8:], zipFileFlags(f.name))
+		binary.LittleEndian.PutUint16(buf[10:], 0) // store method
+		binary.LittleEndian.PutUint16(buf[12:], tm)
+		binary.LittleEndian.PutUint16(buf[14:], date)
+		binary.LittleEndian.PutUint32(buf[16:], f.crc)
+		binary.LittleEndian.PutUint32(buf[20:], size32) // compressed
+		binary.LittleEndian.PutUint32(buf[24:], size32) // uncompressed
+		binary.LittleEndian.PutUint16(buf[28:], uint16(len(f.name)))
+		binary.LittleEndian.PutUint16(buf[30:], uint16(extraLen))
+		// comment length, disk number, internal and external attributes are zero
+		binary.LittleEndian.PutUint32(buf[42:], offset32)
+		buf = append(buf, f.name...)
+		buf = appendExtTime(buf, f.modTime)
+		if f.zip64 {
+			buf = binary.LittleEndian.AppendUint16(buf, zip64ExtraID)
+			buf = binary.LittleEndian.AppendUint16(buf, 24)
+			buf = binary.LittleEndian.AppendUint64(buf, f.size)
+			buf = binary.LittleEndian.AppendUint64(buf, f.size)
+			buf = binary.LittleEndian.AppendUint64(buf, f.offset)
+		}
+
+		if err := z.write(buf); err != nil {
+			return err
+		}
+	}
+
+	end := z.offset
+	records, cdSize := uint64(len(z.files)), end-start
+
+	if useZip64 || records >= zipUint16Max || cdSize >= zipUint32Max || start >= zipUint32Max {
+		buf := binary.LittleEndian.AppendUint32(nil, zip64EndSignature)
+		buf = binary.LittleEndian.AppendUint64(buf, zip64EndLen-12) // without signature and size fields
+		buf = binary.LittleEndian.AppendUint16(buf, zipVersion45)   // made by
+		buf = binary.LittleEndian.AppendUint16(buf, zipVersion45)   // needed to extract
+		buf = binary.LittleEndian.AppendUint32(buf, 0)              // number of this disk
+		buf = binary.LittleEndian.AppendUint32(buf, 0)              // disk with central directory
+		buf = binary.LittleEndian.AppendUint64(buf, records)
+		buf = binary.LittleEndian.AppendUint64(buf, records)
+		buf = binary.LittleEndian.AppendUint64(buf, cdSize)
+		buf = binary.LittleEndian.AppendUint64(buf, start)
+
+		buf = binary.LittleEndian.AppendUint32(buf, zip64LocatorSignature)
+		buf = binary.LittleEndian.AppendUint32(buf, 0) // disk with zip64 end record
+		buf = binary.LittleEndian.AppendUint64(buf, end)
+		buf = binary.LittleEndian.AppendUint32(buf, 1) // total number of disks
+
+		if err := z.write(buf); err != nil {
+			return err
+		}
+	}
+
+	buf := make([]byte, zipEndLen)
+	binary.LittleEndian.PutUint32(buf[0:], zipEndSignature)
+	binary.LittleEndian.PutUint16(buf[8:], uint16(min64(records, zipUint16Max)))
+	binary.LittleEndian.PutUint16(buf[10:], uint16(min64(records, zipUint16Max)))
+	binary.LittleEndian.PutUint32(buf[12:], uint32(min64(cdSize, zipUint32Max)))
+	binary.LittleEndian.PutUint32(buf[16:], uint32(min64(start, zipUint32Max)))
+
+	return z.write(buf)
+}
+
+func min64(a, b uint64) uint64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func zipFileFlags(name string) uint16 {
+	flags := uint16(zipFlagDataDescriptor)
+	if !isASCII(name) {
+		flags |= zipFlagUTF8
+	}
+	return flags
+}
+
+// appendExtTime appends extended timestamp extra field with modification
+// time, it's the same for local and central directory headers.
+func appendExtTime(buf []byte, t time.Time) []byte {
+	buf = binary.LittleEndian.AppendUint16(buf, zipExtTimeID)
+	buf = binary.LittleEndian.AppendUint16(buf, 5)
+	buf = append(buf, 1) // modification time is present
+	return binary.LittleEndian.AppendUint32(buf, uint32(t.Unix()))
+}
+
+// msDosTime converts time to MS-DOS date and time in UTC, times before 1980
+// are not representable and are clamped.
+func msDosTime(t time.Time) (uint16, uint16) {
+	t = t.UTC()
+	if t.Before(zipDOSEpoch) {
+		t = zipDOSEpoch
+	}
+	date := uint16(t.Day() + int(t.Month())<<5 + (t.Year()-1980)<<9)
+	tm := uint16(t.Second()/2 + t.Minute()<<5 + t.Hour()<<11)
+	return date, tm
+}
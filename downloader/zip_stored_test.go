@@ -0,0 +1,161 @@
+package downloader
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/stretchr/testify/require"
+)
+
+func storedZipObject(size uint64, modTime *time.Time) *object.Object {
+	obj := object.New()
+	obj.SetPayloadSize(size)
+	if modTime != nil {
+		attr := object.NewAttribute()
+		attr.SetKey(object.AttributeTimestamp)
+		attr.SetValue(strconv.FormatInt(modTime.Unix(), 10))
+		obj.SetAttributes(*attr)
+	}
+	return obj
+}
+
+func TestStoredZipWriter(t *testing.T) {
+	modTime := time.Unix(1700000000, 0)
+	files := []struct {
+		name    string
+		payload []byte
+		modTime *time.Time
+	}{
+		{name: "dir/file.txt", payload: []byte("content"), modTime: &modTime},
+		{name: "каталог/файл.txt", payload: bytes.Repeat([]byte("data"), 1000)},
+		{name: "empty", payload: nil, modTime: &modTime},
+	}
+
+	var (
+		buf      bytes.Buffer
+		expected []archiveFile
+		aw       = storedZipFormat().newWriter(&buf)
+	)
+	for _, f := range files {
+		w, err := aw.createFile(storedZipObject(uint64(len(f.payload)), f.modTime), f.name)
+		require.NoError(t, err)
+		_, err = w.Write(f.payload)
+		require.NoError(t, err)
+		require.NoError(t, aw.Flush())
+		expected = append(expected, archiveFile{name: f.name, size: uint64(len(f.payload))})
+	}
+	require.NoError(t, aw.Close())
+
+	size, err := storedZipSize(expected)
+	require.NoError(t, err)
+	require.EqualValues(t, buf.Len(), size)
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, zr.File, len(files))
+
+	for i, f := range files {
+		zf := zr.File[i]
+		require.Equal(t, f.name, zf.Name)
+		require.Equal(t, zip.Store, zf.Method)
+		require.EqualValues(t, len(f.payload), zf.UncompressedSize64)
+		require.Equal(t, !isASCII(f.name), zf.Flags&zipFlagUTF8 != 0)
+		if f.modTime != nil {
+			require.True(t, f.modTime.Equal(zf.Modified), zf.Modified)
+		} else {
+			require.True(t, zipDOSEpoch.Equal(zf.Modified), zf.Modified)
+		}
+
+		r, err := zf.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(r) // checks CRC-32 too
+		require.NoError(t, err)
+		require.Equal(t, len(f.payload), len(data))
+		require.NoError(t, r.Close())
+	}
+}
+
+func TestStoredZipWriterSizeMismatch(t *testing.T) {
+	aw := storedZipFormat().newWriter(io.Discard)
+
+	w, err := aw.createFile(storedZipObject(4, nil), "file")
+	require.NoError(t, err)
+	_, err = w.Write([]byte("too long"))
+	require.Error(t, err)
+	_, err = w.Write([]byte("abc"))
+	require.NoError(t, err)
+	require.Error(t, aw.Close())
+}
+
+func TestStoredZipSize64(t *testing.T) {
+	if testing.Short() {
+		t.Skip("writes more than 4 GiB")
+	}
+
+	var (
+		cw    countingWriter
+		aw    = storedZipFormat().newWriter(&cw)
+		chunk = make([]byte, 4<<20)
+		files = []archiveFile{{name: "big", size: 1<<32 + 1}, {name: "after", size: 1}}
+	)
+	for _, f := range files {
+		w, err := aw.createFile(storedZipObject(f.size, nil), f.name)
+		require.NoError(t, err)
+		for left := f.size; left > 0; {
+			n := uint64(len(chunk))
+			if left < n {
+				n = left
+			}
+			_, err = w.Write(chunk[:n])
+			require.NoError(t, err)
+			left -= n
+		}
+	}
+	require.NoError(t, aw.Close())
+
+	size, err := storedZipSize(files)
+	require.NoError(t, err)
+	require.EqualValues(t, cw, size)
+}
+
+func TestStoredZipSizeManyFiles(t *testing.T) {
+	var (
+		buf   bytes.Buffer
+		files = make([]archiveFile, 1<<16)
+		aw    = storedZipFormat().newWriter(&buf)
+	)
+	for i := range files {
+		files[i].name = strconv.Itoa(i)
+		_, err := aw.createFile(storedZipObject(0, nil), files[i].name)
+		require.NoError(t, err)
+	}
+	require.NoError(t, aw.Close())
+
+	size, err := storedZipSize(files)
+	require.NoError(t, err)
+	require.EqualValues(t, buf.Len(), size)
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, zr.File, len(files))
+}
+
+func TestRangeWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := &rangeWriter{w: &buf, skip: 3, left: 4}
+
+	n, err := w.Write([]byte("ab"))
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	n, err = w.Write([]byte("cdefghij"))
+	require.NoError(t, err)
+	require.Equal(t, 8, n)
+	_, err = w.Write([]byte("k"))
+	require.ErrorIs(t, err, errRangeWritten)
+	require.Equal(t, "defg", buf.String())
+}
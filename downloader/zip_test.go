@@ -0,0 +1,123 @@
+package downloader
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sdk-go/object"
+	"github.com/stretchr/testify/require"
+)
+
+// zipLocalHeader contains fields of zip local file header.
+type zipLocalHeader struct {
+	flags uint16
+	crc   uint32
+	csize uint32
+	usize uint32
+}
+
+// zipLocalHeaders parses local file headers of the archive.
+func zipLocalHeaders(t *testing.T, data []byte) []zipLocalHeader {
+	var (
+		res []zipLocalHeader
+		sig = binary.LittleEndian.AppendUint32(nil, zipLocalHeaderSignature)
+	)
+	for {
+		i := bytes.Index(data, sig)
+		if i < 0 {
+			return res
+		}
+		require.GreaterOrEqual(t, len(data)-i, zipLocalHeaderLen)
+		hdr := data[i:]
+		res = append(res, zipLocalHeader{
+			flags: binary.LittleEndian.Uint16(hdr[6:]),
+			crc:   binary.LittleEndian.Uint32(hdr[14:]),
+			csize: binary.LittleEndian.Uint32(hdr[18:]),
+			usize: binary.LittleEndian.Uint32(hdr[22:]),
+		})
+		data = hdr[zipLocalHeaderLen:]
+	}
+}
+
+func TestZipFileHeader(t *testing.T) {
+	payload := []byte("content")
+	modTime := time.Unix(1700000000, 0)
+
+	obj := object.New()
+	obj.SetPayloadSize(uint64(len(payload)))
+	attr := object.NewAttribute()
+	attr.SetKey(object.AttributeTimestamp)
+	attr.SetValue(strconv.FormatInt(modTime.Unix(), 10))
+	obj.SetAttributes(*attr)
+
+	names := []string{"dir/file.txt", "каталог/файл.txt", "dir/buffered.txt", "каталог/буфер.txt"}
+
+	for _, compression := range []bool{false, true} {
+		var buf bytes.Buffer
+		aw := zipFormat(compression).newWriter(&buf)
+		for i, name := range names {
+			var r io.Reader = bytes.NewReader(payload)
+			if i >= 2 {
+				r = bufferedPayload{Reader: bytes.NewReader(payload), data: payload}
+			}
+			require.NoError(t, writeArchiveFile(aw, obj, name, r, nil))
+		}
+		require.NoError(t, aw.Close())
+
+		zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		require.NoError(t, err)
+		require.Len(t, zr.File, len(names))
+
+		for i, f := range zr.File {
+			require.Equal(t, names[i], f.Name)
+			require.True(t, modTime.Equal(f.Modified))
+			require.EqualValues(t, len(payload), f.UncompressedSize64)
+			require.Equal(t, !isASCII(names[i]), f.Flags&zipFlagUTF8 != 0)
+
+			rc, err := f.Open()
+			require.NoError(t, err)
+			data, err := io.ReadAll(rc)
+			require.NoError(t, err)
+			require.NoError(t, rc.Close())
+			require.Equal(t, payload, data)
+		}
+
+		headers := zipLocalHeaders(t, buf.Bytes())
+		require.Len(t, headers, len(names))
+		for i, hdr := range headers {
+			if i < 2 || compression {
+				// streamed and deflated files have data descriptors
+				require.NotZero(t, hdr.flags&zipFlagDataDescriptor, names[i])
+				require.Equal(t, zipLocalHeader{flags: hdr.flags}, hdr, names[i])
+				continue
+			}
+			require.Zero(t, hdr.flags&zipFlagDataDescriptor, names[i])
+			require.Equal(t, crc32.ChecksumIEEE(payload), hdr.crc)
+			require.EqualValues(t, len(payload), hdr.csize)
+			require.EqualValues(t, len(payload), hdr.usize)
+		}
+	}
+}
+
+func TestZipManyFiles(t *testing.T) {
+	const count = 1<<16 + 1
+
+	var buf bytes.Buffer
+	aw := zipFormat(false).newWriter(&buf)
+	obj := object.New()
+	for i := 0; i < count; i++ {
+		_, err := aw.createFile(obj, strconv.Itoa(i))
+		require.NoError(t, err)
+	}
+	require.NoError(t, aw.Close())
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+	require.Len(t, zr.File, count)
+}
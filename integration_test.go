@@ -1,8 +1,10 @@
 package main
 
 import (
+	"archive/tar"
 	"archive/zip"
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -69,6 +71,7 @@ func TestIntegration(t *testing.T) {
 		t.Run("get by attribute "+image, func(t *testing.T) { getByAttr(ctx, t, clientPool, ownerID, CID, signer) })
 		t.Run("get by attribute, not found "+image, func(t *testing.T) { getByAttrNotFound(t) })
 		t.Run("get zip "+image, func(t *testing.T) { getZip(ctx, t, clientPool, ownerID, CID, signer) })
+		t.Run("get tar "+image, func(t *testing.T) { getTar(ctx, t, clientPool, ownerID, CID, signer) })
 
 		cancel()
 		server.Wait()
@@ -330,6 +333,58 @@ func checkZip(t *testing.T, data []byte, length int64, names, contents []string)
 	}
 }
 
+func getTar(ctx context.Context, t *testing.T, clientPool *pool.Pool, ownerID user.ID, CID cid.ID, signer user.Signer) {
+	names := []string{"tarfolder/dir/name1.txt", "tarfolder/name2.txt"}
+	contents := []string{"content of file1", "content of file2"}
+	attributes1 := map[string]string{object.AttributeFilePath: names[0]}
+	attributes2 := map[string]string{object.AttributeFilePath: names[1]}
+
+	putObject(ctx, t, clientPool, ownerID, CID, contents[0], attributes1, signer)
+	putObject(ctx, t, clientPool, ownerID, CID, contents[1], attributes2, signer)
+
+	baseURL := testHost + "/tar/" + testContainerName
+	makeTarRequest(t, baseURL+"/tarfolder", false, names, contents)
+	makeTarRequest(t, baseURL+"/tarfolder?format=tar.gz", true, names, contents)
+
+	// check nested folder
+	makeTarRequest(t, baseURL+"/tarfolder/dir", false, names[:1], contents[:1])
+}
+
+func makeTarRequest(t *testing.T, url string, gzipped bool, names, contents []string) {
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer func() {
+		err := resp.Body.Close()
+		require.NoError(t, err)
+	}()
+
+	var r io.Reader = resp.Body
+	if gzipped {
+		r, err = gzip.NewReader(resp.Body)
+		require.NoError(t, err)
+	}
+
+	tarReader := tar.NewReader(r)
+	files := make(map[string]string)
+	for {
+		hdr, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		data, err := io.ReadAll(tarReader)
+		require.NoError(t, err)
+		require.EqualValues(t, len(data), hdr.Size)
+		files[hdr.Name] = string(data)
+	}
+
+	require.Len(t, files, len(names))
+	for i := range names {
+		require.Equal(t, contents[i], files[names[i]])
+	}
+}
+
 func createDockerContainer(ctx context.Context, t *testing.T, image string) testcontainers.Container {
 	req := testcontainers.ContainerRequest{
 		Image:      image,
@@ -14,6 +14,7 @@ const (
 	namespace      = "neofs_http_gw"
 	stateSubsystem = "state"
 	poolSubsystem  = "pool"
+	archSubsystem  = "archive"
 
 	methodGetBalance       = "get_balance"
 	methodPutContainer     = "put_container"
@@ -35,6 +36,7 @@ const (
 type GateMetrics struct {
 	stateMetrics
 	poolMetricsCollector
+	archiveMetrics
 }
 
 type stateMetrics struct {
@@ -42,6 +44,11 @@ type stateMetrics struct {
 	gwVersion   *prometheus.GaugeVec
 }
 
+type archiveMetrics struct {
+	objects *prometheus.CounterVec
+	streams *prometheus.CounterVec
+}
+
 type poolMetricsCollector struct {
 	pool                *pool.Pool
 	statistic           *stat.PoolStat
@@ -60,15 +67,20 @@ func NewGateMetrics(p *pool.Pool, statistic *stat.PoolStat) *GateMetrics {
 	poolMetric := newPoolMetricsCollector(p, statistic)
 	poolMetric.register()
 
+	archMetric := newArchiveMetrics()
+	archMetric.register()
+
 	return &GateMetrics{
 		stateMetrics:         *stateMetric,
 		poolMetricsCollector: *poolMetric,
+		archiveMetrics:       *archMetric,
 	}
 }
 
 func (g *GateMetrics) Unregister() {
 	g.stateMetrics.unregister()
 	prometheus.Unregister(&g.poolMetricsCollector)
+	g.archiveMetrics.unregister()
 }
 
 func newStateMetrics() *stateMetrics {
@@ -104,6 +116,54 @@ func (m stateMetrics) SetHealth(s int32) {
 	m.healthCheck.Set(float64(s))
 }
 
+func newArchiveMetrics() *archiveMetrics {
+	return &archiveMetrics{
+		objects: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: archSubsystem,
+				Name:      "objects_total",
+				Help:      "Number of objects processed for archive downloads",
+			},
+			[]string{"status"},
+		),
+		streams: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: archSubsystem,
+				Name:      "streams_total",
+				Help:      "Number of finished archive downloads",
+			},
+			[]string{"status"},
+		),
+	}
+}
+
+func (m archiveMetrics) register() {
+	prometheus.MustRegister(m.objects)
+	prometheus.MustRegister(m.streams)
+}
+
+func (m archiveMetrics) unregister() {
+	prometheus.Unregister(m.objects)
+	prometheus.Unregister(m.streams)
+}
+
+// ArchiveCompleted counts archive stream result: complete, partial (some
+// objects are skipped) or aborted.
+func (m archiveMetrics) ArchiveCompleted(added, failed int, aborted bool) {
+	m.objects.WithLabelValues("added").Add(float64(added))
+	m.objects.WithLabelValues("failed").Add(float64(failed))
+
+	status := "complete"
+	if aborted {
+		status = "aborted"
+	} else if failed != 0 {
+		status = "partial"
+	}
+	m.streams.WithLabelValues(status).Inc()
+}
+
 func newPoolMetricsCollector(p *pool.Pool, statistic *stat.PoolStat) *poolMetricsCollector {
 	overallErrors := prometheus.NewGauge(
 		prometheus.GaugeOpts{
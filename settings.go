@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"net/http"
 	"os"
 	"runtime"
 	"sort"
@@ -9,6 +10,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/nspcc-dev/neofs-http-gw/downloader"
+	cid "github.com/nspcc-dev/neofs-sdk-go/container/id"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"github.com/valyala/fasthttp"
@@ -26,6 +29,16 @@ const (
 
 	defaultPoolErrorThreshold uint32 = 100
 
+	defaultCacheByAddress   = "public, max-age=31536000, immutable"
+	defaultCacheByAttribute = "public, max-age=60"
+
+	defaultZipConcurrency  = 4
+	defaultZipMemoryBudget = 16 << 20
+
+	defaultCompressionMinSize = 1024
+
+	defaultWebsiteIndexDocument = "index.html"
+
 	cfgServer      = "server"
 	cfgTLSEnabled  = "tls.enabled"
 	cfgTLSCertFile = "tls.cert_file"
@@ -63,14 +76,52 @@ const (
 	// Uploader Header.
 	cfgUploaderHeaderEnableDefaultTimestamp = "upload_header.use_default_timestamp"
 
+	// Downloader Header.
+	cfgDownloaderHeaderAttributes = "download_header.attributes"
+
 	// Peers.
 	cfgPeers = "peers"
 
 	// NeoGo.
 	cfgRPCEndpoint = "rpc_endpoint"
 
-	// Zip compression.
-	cfgZipCompression = "zip.compression"
+	// Zip.
+	cfgZipCompression   = "zip.compression"
+	cfgZipConcurrency   = "zip.concurrency"
+	cfgZipMemoryBudget  = "zip.memory_budget"
+	cfgZipOnError       = "zip.on_error"
+	cfgZipContentLength = "zip.content_length"
+	cfgZipMaxObjects    = "zip.max_objects"
+	cfgZipMaxSize       = "zip.max_size"
+	cfgZipMaxDuration   = "zip.max_duration"
+	cfgZipMaxStreams    = "zip.max_streams"
+
+	// Caching.
+	cfgCacheByAddress   = "cache.by_address"
+	cfgCacheByAttribute = "cache.by_attribute"
+	cfgCacheContainers  = "cache.containers"
+
+	// Website.
+	cfgWebsiteIndexDocument = "website.index_document"
+	cfgWebsiteErrorDocument = "website.error_document"
+	cfgWebsiteSPAFallback   = "website.spa_fallback"
+	cfgWebsiteContainers    = "website.containers"
+
+	// Directory index.
+	cfgDirectoryIndexEnabled    = "directory_index.enabled"
+	cfgDirectoryIndexTemplate   = "directory_index.template"
+	cfgDirectoryIndexContainers = "directory_index.containers"
+
+	// Virtual hosts.
+	cfgVirtualHostsHosts   = "virtual_hosts.hosts"
+	cfgVirtualHostsDomains = "virtual_hosts.domains"
+	cfgVirtualHostsResolve = "virtual_hosts.resolve_hosts"
+
+	// Compression.
+	cfgCompressionEnabled   = "compression.enabled"
+	cfgCompressionMinSize   = "compression.min_size"
+	cfgCompressionTypes     = "compression.types"
+	cfgCompressionEncodings = "compression.encodings"
 
 	// Command line args.
 	cmdHelp          = "help"
@@ -140,8 +191,55 @@ func settings() *viper.Viper {
 	// upload header
 	v.SetDefault(cfgUploaderHeaderEnableDefaultTimestamp, false)
 
+	// downloader:
+	v.SetDefault(cfgDownloaderHeaderAttributes, []string{
+		fasthttp.HeaderContentEncoding,
+		fasthttp.HeaderContentLanguage,
+		fasthttp.HeaderCacheControl,
+		fasthttp.HeaderContentDisposition,
+	})
+
 	// zip:
 	v.SetDefault(cfgZipCompression, false)
+	v.SetDefault(cfgZipConcurrency, defaultZipConcurrency)
+	v.SetDefault(cfgZipMemoryBudget, defaultZipMemoryBudget)
+	v.SetDefault(cfgZipOnError, "skip")
+	v.SetDefault(cfgZipContentLength, false)
+	v.SetDefault(cfgZipMaxObjects, 0)
+	v.SetDefault(cfgZipMaxSize, 0)
+	v.SetDefault(cfgZipMaxDuration, 0)
+	v.SetDefault(cfgZipMaxStreams, 0)
+
+	// cache:
+	v.SetDefault(cfgCacheByAddress, defaultCacheByAddress)
+	v.SetDefault(cfgCacheByAttribute, defaultCacheByAttribute)
+
+	// website:
+	v.SetDefault(cfgWebsiteIndexDocument, defaultWebsiteIndexDocument)
+	v.SetDefault(cfgWebsiteErrorDocument, "")
+	v.SetDefault(cfgWebsiteSPAFallback, false)
+
+	// directory index:
+	v.SetDefault(cfgDirectoryIndexEnabled, false)
+	v.SetDefault(cfgDirectoryIndexTemplate, "")
+
+	// virtual hosts:
+	v.SetDefault(cfgVirtualHostsResolve, false)
+
+	// compression:
+	v.SetDefault(cfgCompressionEnabled, false)
+	v.SetDefault(cfgCompressionMinSize, defaultCompressionMinSize)
+	v.SetDefault(cfgCompressionTypes, []string{
+		"text/*",
+		"application/json",
+		"application/x-ndjson",
+		"application/javascript",
+		"application/xml",
+		"application/yaml",
+		"application/wasm",
+		"image/svg+xml",
+	})
+	v.SetDefault(cfgCompressionEncodings, []string{"zstd", "br", "gzip"})
 
 	// metrics
 	v.SetDefault(cfgPprofAddress, "localhost:8083")
@@ -325,3 +423,202 @@ func fetchServers(v *viper.Viper) []ServerInfo {
 
 	return servers
 }
+
+func fetchCachePolicies(l *zap.Logger, v *viper.Viper) (downloader.CachePolicy, map[cid.ID]downloader.CachePolicy) {
+	global := downloader.CachePolicy{
+		ByAddress:   v.GetString(cfgCacheByAddress),
+		ByAttribute: v.GetString(cfgCacheByAttribute),
+	}
+	containers := make(map[cid.ID]downloader.CachePolicy)
+
+	for i := 0; ; i++ {
+		key := cfgCacheContainers + "." + strconv.Itoa(i) + "."
+
+		cnrStr := v.GetString(key + "container")
+		if cnrStr == "" {
+			break
+		}
+
+		var cnrID cid.ID
+		if err := cnrID.DecodeString(cnrStr); err != nil {
+			l.Warn("invalid container id in cache configuration", zap.String("container", cnrStr), zap.Error(err))
+			continue
+		}
+
+		containers[cnrID] = downloader.CachePolicy{
+			ByAddress:   v.GetString(key + "by_address"),
+			ByAttribute: v.GetString(key + "by_attribute"),
+		}
+	}
+
+	return global, containers
+}
+
+func fetchWebsites(l *zap.Logger, v *viper.Viper) (downloader.WebsiteConfig, map[cid.ID]downloader.WebsiteConfig) {
+	global := downloader.WebsiteConfig{
+		IndexDocument: v.GetString(cfgWebsiteIndexDocument),
+		ErrorDocument: v.GetString(cfgWebsiteErrorDocument),
+		SPAFallback:   v.GetBool(cfgWebsiteSPAFallback),
+	}
+	containers := make(map[cid.ID]downloader.WebsiteConfig)
+
+	for i := 0; ; i++ {
+		key := cfgWebsiteContainers + "." + strconv.Itoa(i) + "."
+
+		cnrStr := v.GetString(key + "container")
+		if cnrStr == "" {
+			break
+		}
+
+		var cnrID cid.ID
+		if err := cnrID.DecodeString(cnrStr); err != nil {
+			l.Warn("invalid container id in website configuration", zap.String("container", cnrStr), zap.Error(err))
+			continue
+		}
+
+		cfg := global
+		if v.IsSet(key + "index_document") {
+			cfg.IndexDocument = v.GetString(key + "index_document")
+		}
+		if v.IsSet(key + "error_document") {
+			cfg.ErrorDocument = v.GetString(key + "error_document")
+		}
+		if v.IsSet(key + "spa_fallback") {
+			cfg.SPAFallback = v.GetBool(key + "spa_fallback")
+		}
+		containers[cnrID] = cfg
+	}
+
+	return global, containers
+}
+
+func fetchDirectoryIndex(l *zap.Logger, v *viper.Viper) *downloader.DirectoryIndex {
+	containers := make(map[cid.ID]bool)
+	for i := 0; ; i++ {
+		key := cfgDirectoryIndexContainers + "." + strconv.Itoa(i) + "."
+
+		cnrStr := v.GetString(key + "container")
+		if cnrStr == "" {
+			break
+		}
+
+		var cnrID cid.ID
+		if err := cnrID.DecodeString(cnrStr); err != nil {
+			l.Warn("invalid container id in directory index configuration", zap.String("container", cnrStr), zap.Error(err))
+			continue
+		}
+		containers[cnrID] = v.GetBool(key + "enabled")
+	}
+
+	var tmpl string
+	if file := v.GetString(cfgDirectoryIndexTemplate); file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			l.Warn("could not read directory index template, default one is used", zap.String("file", file), zap.Error(err))
+		}
+		tmpl = string(data)
+	}
+
+	index, err := downloader.NewDirectoryIndex(tmpl, v.GetBool(cfgDirectoryIndexEnabled), containers)
+	if err != nil {
+		l.Warn("invalid directory index template, default one is used", zap.Error(err))
+		index, _ = downloader.NewDirectoryIndex("", v.GetBool(cfgDirectoryIndexEnabled), containers)
+	}
+
+	return index
+}
+
+func fetchVirtualHosts(l *zap.Logger, v *viper.Viper) *downloader.VirtualHosts {
+	vhosts := &downloader.VirtualHosts{
+		Hosts:        make(map[string]string),
+		ResolveHosts: v.GetBool(cfgVirtualHostsResolve),
+	}
+
+	for i := 0; ; i++ {
+		key := cfgVirtualHostsHosts + "." + strconv.Itoa(i) + "."
+
+		host := strings.ToLower(strings.TrimSuffix(v.GetString(key+"host"), "."))
+		if host == "" {
+			break
+		}
+
+		cnr := v.GetString(key + "container")
+		if cnr == "" {
+			l.Warn("empty container in virtual host configuration", zap.String("host", host))
+			continue
+		}
+		vhosts.Hosts[host] = cnr
+	}
+
+	for _, domain := range v.GetStringSlice(cfgVirtualHostsDomains) {
+		domain = strings.ToLower(strings.Trim(domain, "."))
+		if domain != "" {
+			vhosts.Domains = append(vhosts.Domains, domain)
+		}
+	}
+
+	if len(vhosts.Hosts) == 0 && len(vhosts.Domains) == 0 && !vhosts.ResolveHosts {
+		return nil
+	}
+
+	return vhosts
+}
+
+func fetchCompression(l *zap.Logger, v *viper.Viper) *downloader.Compression {
+	if !v.GetBool(cfgCompressionEnabled) {
+		return nil
+	}
+
+	compression, err := downloader.NewCompression(
+		v.GetUint64(cfgCompressionMinSize),
+		v.GetStringSlice(cfgCompressionTypes),
+		v.GetStringSlice(cfgCompressionEncodings),
+	)
+	if err != nil {
+		l.Warn("invalid compression configuration, compression is disabled", zap.Error(err))
+		return nil
+	}
+
+	return compression
+}
+
+// reservedHeaders can't be set from object attributes, since they are
+// controlled by the gateway.
+var reservedHeaders = map[string]struct{}{
+	fasthttp.HeaderAcceptRanges:      {},
+	fasthttp.HeaderConnection:        {},
+	fasthttp.HeaderContentLength:     {},
+	fasthttp.HeaderContentRange:      {},
+	fasthttp.HeaderContentType:       {},
+	fasthttp.HeaderETag:              {},
+	fasthttp.HeaderLastModified:      {},
+	fasthttp.HeaderTransferEncoding:  {},
+	fasthttp.HeaderTrailer:           {},
+	fasthttp.HeaderUpgrade:           {},
+	fasthttp.HeaderKeepAlive:         {},
+	fasthttp.HeaderProxyAuthenticate: {},
+}
+
+func fetchAttributeHeaders(l *zap.Logger, v *viper.Viper) []string {
+	var res []string
+	for _, name := range v.GetStringSlice(cfgDownloaderHeaderAttributes) {
+		name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+		if _, ok := reservedHeaders[name]; ok || !isValidHeaderName(name) {
+			l.Warn("invalid attribute header is ignored", zap.String("header", name))
+			continue
+		}
+		res = append(res, name)
+	}
+
+	return res
+}
+
+// isValidHeaderName checks that the name is a valid HTTP token.
+func isValidHeaderName(name string) bool {
+	for _, c := range name {
+		if c <= ' ' || c > '~' || strings.ContainsRune(`"(),/:;<=>?@[\]{}`, c) {
+			return false
+		}
+	}
+	return name != ""
+}
@@ -78,7 +78,7 @@ func filterHeaders(l *zap.Logger, header *fasthttp.RequestHeader) (map[string]st
 	return result, err
 }
 
-func prepareExpirationHeader(headers map[string]string, epochDurations *epochDurations, now time.Time) error {
+func prepareExpirationHeader(headers map[string]string, epochDurations *utils.EpochDurations, now time.Time) error {
 	expirationInEpoch := headers[object.AttributeExpirationEpoch]
 
 	if timeRFC3339, ok := headers[utils.ExpirationRFC3339Attr]; ok {
@@ -127,9 +127,9 @@ func prepareExpirationHeader(headers map[string]string, epochDurations *epochDur
 	return nil
 }
 
-func updateExpirationHeader(headers map[string]string, durations *epochDurations, expDuration time.Duration) {
-	epochDuration := uint64(durations.msPerBlock) * durations.blockPerEpoch
-	currentEpoch := durations.currentEpoch
+func updateExpirationHeader(headers map[string]string, durations *utils.EpochDurations, expDuration time.Duration) {
+	epochDuration := uint64(durations.MsPerBlock) * durations.BlockPerEpoch
+	currentEpoch := durations.CurrentEpoch
 	numEpoch := uint64(expDuration.Milliseconds()) / epochDuration
 
 	if uint64(expDuration.Milliseconds())%epochDuration != 0 {
@@ -67,24 +67,24 @@ func TestPrepareExpirationHeader(t *testing.T) {
 	timestampMilli := strconv.FormatInt(tomorrowUnixMilli, 10)
 	timestampNano := strconv.FormatInt(tomorrowUnixNano, 10)
 
-	defaultDurations := &epochDurations{
-		currentEpoch:  10,
-		msPerBlock:    1000,
-		blockPerEpoch: 101,
+	defaultDurations := &utils.EpochDurations{
+		CurrentEpoch:  10,
+		MsPerBlock:    1000,
+		BlockPerEpoch: 101,
 	}
 
-	msPerBlock := defaultDurations.blockPerEpoch * uint64(defaultDurations.msPerBlock)
+	msPerBlock := defaultDurations.BlockPerEpoch * uint64(defaultDurations.MsPerBlock)
 	epochPerDay := uint64((24 * time.Hour).Milliseconds()) / msPerBlock
 	if uint64((24*time.Hour).Milliseconds())%msPerBlock != 0 {
 		epochPerDay++
 	}
 
-	defaultExpEpoch := strconv.FormatUint(defaultDurations.currentEpoch+epochPerDay, 10)
+	defaultExpEpoch := strconv.FormatUint(defaultDurations.CurrentEpoch+epochPerDay, 10)
 
 	for _, tc := range []struct {
 		name      string
 		headers   map[string]string
-		durations *epochDurations
+		durations *utils.EpochDurations
 		err       bool
 		expected  map[string]string
 	}{
@@ -159,10 +159,10 @@ func TestPrepareExpirationHeader(t *testing.T) {
 		{
 			name:    "valid max uint 64",
 			headers: map[string]string{utils.ExpirationRFC3339Attr: tomorrow.Format(time.RFC3339)},
-			durations: &epochDurations{
-				currentEpoch:  math.MaxUint64 - 1,
-				msPerBlock:    defaultDurations.msPerBlock,
-				blockPerEpoch: defaultDurations.blockPerEpoch,
+			durations: &utils.EpochDurations{
+				CurrentEpoch:  math.MaxUint64 - 1,
+				MsPerBlock:    defaultDurations.MsPerBlock,
+				BlockPerEpoch: defaultDurations.BlockPerEpoch,
 			},
 			expected: map[string]string{object.AttributeExpirationEpoch: strconv.FormatUint(uint64(math.MaxUint64), 10)},
 		},
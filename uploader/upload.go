@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
 	"net/http"
 	"strconv"
@@ -41,12 +40,6 @@ type Uploader struct {
 	signer            user.Signer
 }
 
-type epochDurations struct {
-	currentEpoch  uint64
-	msPerBlock    int64
-	blockPerEpoch uint64
-}
-
 // Settings stores reloading parameters, so it has to provide atomic getters and setters.
 type Settings struct {
 	defaultTimestamp atomic.Bool
@@ -130,7 +123,7 @@ func (u *Uploader) Upload(c *fasthttp.RequestCtx) {
 		return
 	}
 	if needParseExpiration(filtered) {
-		epochDuration, err := getEpochDurations(c, u.pool)
+		epochDuration, err := utils.GetEpochDurations(c, u.pool)
 		if err != nil {
 			log.Error("could not get epoch durations from network info", zap.Error(err))
 			response.Error(c, "could not get epoch durations from network info: "+err.Error(), fasthttp.StatusBadRequest)
@@ -270,24 +263,6 @@ func (pr *putResponse) encode(w io.Writer) error {
 	return enc.Encode(pr)
 }
 
-func getEpochDurations(ctx context.Context, p *pool.Pool) (*epochDurations, error) {
-	networkInfo, err := p.NetworkInfo(ctx, client.PrmNetworkInfo{})
-	if err != nil {
-		return nil, err
-	}
-
-	res := &epochDurations{
-		currentEpoch:  networkInfo.CurrentEpoch(),
-		msPerBlock:    networkInfo.MsPerBlock(),
-		blockPerEpoch: networkInfo.EpochDuration(),
-	}
-
-	if res.blockPerEpoch == 0 {
-		return nil, fmt.Errorf("EpochDuration is empty")
-	}
-	return res, nil
-}
-
 func needParseExpiration(headers map[string]string) bool {
 	_, ok1 := headers[utils.ExpirationDurationAttr]
 	_, ok2 := headers[utils.ExpirationRFC3339Attr]
@@ -0,0 +1,48 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nspcc-dev/neofs-sdk-go/client"
+	"github.com/nspcc-dev/neofs-sdk-go/netmap"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+)
+
+// EpochDurations contains network parameters required to convert epochs
+// to time and vice versa.
+type EpochDurations struct {
+	CurrentEpoch  uint64
+	MsPerBlock    int64
+	BlockPerEpoch uint64
+}
+
+// GetEpochDurations fetches epoch parameters from the network info.
+func GetEpochDurations(ctx context.Context, p *pool.Pool) (*EpochDurations, error) {
+	networkInfo, err := p.NetworkInfo(ctx, client.PrmNetworkInfo{})
+	if err != nil {
+		return nil, err
+	}
+
+	return NewEpochDurations(networkInfo)
+}
+
+// NewEpochDurations gets epoch parameters from the network info.
+func NewEpochDurations(networkInfo netmap.NetworkInfo) (*EpochDurations, error) {
+	res := &EpochDurations{
+		CurrentEpoch:  networkInfo.CurrentEpoch(),
+		MsPerBlock:    networkInfo.MsPerBlock(),
+		BlockPerEpoch: networkInfo.EpochDuration(),
+	}
+
+	if res.BlockPerEpoch == 0 {
+		return nil, fmt.Errorf("EpochDuration is empty")
+	}
+	return res, nil
+}
+
+// EpochDuration returns the duration of a single epoch.
+func (d *EpochDurations) EpochDuration() time.Duration {
+	return time.Duration(uint64(d.MsPerBlock)*d.BlockPerEpoch) * time.Millisecond
+}